@@ -0,0 +1,87 @@
+package dap
+
+import "encoding/json"
+
+// the parts of the protocol the server uses, see
+// https://microsoft.github.io/debug-adapter-protocol/specification
+
+type request struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type response struct {
+	Seq        int    `json:"seq"`
+	Type       string `json:"type"`
+	RequestSeq int    `json:"request_seq"`
+	Success    bool   `json:"success"`
+	Command    string `json:"command"`
+	Message    string `json:"message,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+type event struct {
+	Seq   int    `json:"seq"`
+	Type  string `json:"type"`
+	Event string `json:"event"`
+	Body  any    `json:"body,omitempty"`
+}
+
+type source struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type breakpoint struct {
+	Verified bool `json:"verified"`
+	Line     int  `json:"line"`
+}
+
+type thread struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type stackFrame struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Source source `json:"source"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type scope struct {
+	Name               string `json:"name"`
+	PresentationHint   string `json:"presentationHint,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+	Expensive          bool   `json:"expensive"`
+}
+
+type variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+type stoppedEvent struct {
+	Reason            string `json:"reason"`
+	ThreadID          int    `json:"threadId"`
+	AllThreadsStopped bool   `json:"allThreadsStopped"`
+}
+
+type outputEvent struct {
+	Category string `json:"category"`
+	Output   string `json:"output"`
+}
+
+type exitedEvent struct {
+	ExitCode int `json:"exitCode"`
+}
+
+var capabilities = map[string]any{
+	"supportsConfigurationDoneRequest": true,
+	"supportsEvaluateForHovers":        true,
+	"supportsTerminateRequest":         true,
+}
@@ -0,0 +1,481 @@
+// Package dap is a debug adapter for Lox, it speaks the Debug Adapter
+// Protocol over a pair of streams and runs the script it is asked to launch
+// on the tree-walking interpreter under a lox.Debugger
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/daliborpovolny/lox/glox/glox/lox"
+)
+
+// the script runs as the only thread
+const threadID = 1
+
+var errNotPaused = errors.New("The program is not paused.")
+
+type server struct {
+	in *textproto.Reader
+
+	// guards the output and the sequence numbers, events are also sent from
+	// the goroutine of the script, nothing is sent once the client is gone
+	mu     sync.Mutex
+	out    io.Writer
+	seq    int
+	closed bool
+
+	// lines and columns of the client start at 0 or at 1 like the Lox ones
+	lineBase   int
+	columnBase int
+
+	interpreter *lox.Interpreter
+	debugger    *lox.Debugger
+
+	// set by launch
+	program     string
+	source      string
+	stopOnEntry bool
+	noDebug     bool
+
+	launched   bool
+	configured bool
+	started    bool
+
+	// while the script is paused its goroutine runs the functions sent on
+	// work, until one of them returns true or stopping is closed
+	paused   atomic.Bool
+	work     chan func() bool
+	stopping chan struct{}
+
+	// closed once the script has finished
+	done chan struct{}
+
+	// scopes handed out to the client since the script last paused, the
+	// variables reference of a scope is its index plus one
+	references []reference
+
+	// called once the response to the request being handled is sent
+	afterReply func()
+}
+
+type reference struct {
+	frame int
+	scope int
+}
+
+// Serve reads requests from in and writes responses and events to out until
+// the client disconnects or closes in, the script launched is stopped then,
+// it fails when a stream breaks
+func Serve(in io.Reader, out io.Writer) error {
+	s := &server{
+		in:          textproto.NewReader(bufio.NewReader(in)),
+		out:         out,
+		lineBase:    1,
+		columnBase:  1,
+		interpreter: lox.NewInterpreter(),
+		work:        make(chan func() bool),
+		stopping:    make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	s.debugger = lox.NewDebugger(s.interpreter, s.pausedAt)
+
+	// the standard streams carry the protocol
+	s.interpreter.SetOutput(output{s, "stdout"})
+	s.interpreter.SetInput(strings.NewReader(""))
+
+	defer func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		s.stop()
+	}()
+
+	for {
+		req, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		body, err := s.handle(req)
+		if err := s.reply(req, body, err); err != nil {
+			return err
+		}
+
+		if s.afterReply != nil {
+			s.afterReply()
+			s.afterReply = nil
+		}
+		if req.Command == "disconnect" {
+			return nil
+		}
+	}
+}
+
+func (s *server) read() (*request, error) {
+	header, err := s.in.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in.R, body); err != nil {
+		return nil, err
+	}
+
+	req := &request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// writes a response or an event, their sequence number is set here
+func (s *server) write(msg any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	s.seq++
+	switch msg := msg.(type) {
+	case *response:
+		msg.Seq = s.seq
+	case *event:
+		msg.Seq = s.seq
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *server) reply(req *request, body any, err error) error {
+	resp := &response{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+	if err != nil {
+		resp.Message = err.Error()
+	}
+	return s.write(resp)
+}
+
+// a broken output stream shows up on the next response
+func (s *server) event(name string, body any) {
+	_ = s.write(&event{Type: "event", Event: name, Body: body})
+}
+
+// output sends what the script prints to the client
+type output struct {
+	s        *server
+	category string
+}
+
+func (o output) Write(p []byte) (int, error) {
+	o.s.event("output", outputEvent{o.category, string(p)})
+	return len(p), nil
+}
+
+func (s *server) handle(req *request) (any, error) {
+	switch req.Command {
+	case "initialize":
+		var args struct {
+			LinesStartAt1   *bool `json:"linesStartAt1"`
+			ColumnsStartAt1 *bool `json:"columnsStartAt1"`
+		}
+		if err := unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if args.LinesStartAt1 != nil && !*args.LinesStartAt1 {
+			s.lineBase = 0
+		}
+		if args.ColumnsStartAt1 != nil && !*args.ColumnsStartAt1 {
+			s.columnBase = 0
+		}
+		s.afterReply = func() { s.event("initialized", nil) }
+		return capabilities, nil
+
+	case "launch":
+		var args struct {
+			Program     string `json:"program"`
+			StopOnEntry bool   `json:"stopOnEntry"`
+			NoDebug     bool   `json:"noDebug"`
+		}
+		if err := unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		if s.launched {
+			return nil, errors.New("The program is already launched.")
+		}
+		source, err := os.ReadFile(args.Program)
+		if err != nil {
+			return nil, err
+		}
+		s.program, s.source = args.Program, string(source)
+		s.stopOnEntry, s.noDebug = args.StopOnEntry, args.NoDebug
+		s.launched = true
+		s.afterReply = s.start
+		return nil, nil
+
+	case "configurationDone":
+		s.configured = true
+		s.afterReply = s.start
+		return nil, nil
+
+	case "setBreakpoints":
+		var args struct {
+			Source      source `json:"source"`
+			Breakpoints []struct {
+				Line int `json:"line"`
+			} `json:"breakpoints"`
+		}
+		if err := unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		lines := make([]int, len(args.Breakpoints))
+		breakpoints := make([]breakpoint, len(args.Breakpoints))
+		for i, b := range args.Breakpoints {
+			lines[i] = b.Line - s.lineBase + 1
+			breakpoints[i] = breakpoint{true, b.Line}
+		}
+		s.debugger.SetBreakpoints(args.Source.Path, lines)
+		return map[string]any{"breakpoints": breakpoints}, nil
+
+	case "setExceptionBreakpoints":
+		return map[string]any{"breakpoints": []breakpoint{}}, nil
+
+	case "threads":
+		return map[string]any{"threads": []thread{{threadID, "main"}}}, nil
+
+	case "pause":
+		s.debugger.Pause()
+		return nil, nil
+
+	case "continue":
+		return map[string]any{"allThreadsContinued": true}, s.resume(s.debugger.Continue)
+	case "next":
+		return nil, s.resume(s.debugger.StepOver)
+	case "stepIn":
+		return nil, s.resume(s.debugger.StepIn)
+	case "stepOut":
+		return nil, s.resume(s.debugger.StepOut)
+
+	case "stackTrace":
+		frames := []stackFrame{}
+		err := s.onScript(func() {
+			for i, frame := range s.debugger.Frames() {
+				frames = append(frames, stackFrame{
+					ID:     i + 1,
+					Name:   frame.Function,
+					Source: sourceOf(frame.File),
+					Line:   frame.Line - 1 + s.lineBase,
+					Column: max(frame.Column, 1) - 1 + s.columnBase,
+				})
+			}
+		})
+		return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, err
+
+	case "scopes":
+		var args struct {
+			FrameID int `json:"frameId"`
+		}
+		if err := unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		scopes := []scope{}
+		err := s.onScript(func() {
+			for i, debugScope := range s.debugger.Scopes(args.FrameID - 1) {
+				s.references = append(s.references, reference{args.FrameID - 1, i})
+				hint := ""
+				if debugScope.Name == "locals" {
+					hint = "locals"
+				}
+				scopes = append(scopes, scope{
+					Name:               strings.ToUpper(debugScope.Name[:1]) + debugScope.Name[1:],
+					PresentationHint:   hint,
+					VariablesReference: len(s.references),
+				})
+			}
+		})
+		return map[string]any{"scopes": scopes}, err
+
+	case "variables":
+		var args struct {
+			VariablesReference int `json:"variablesReference"`
+		}
+		if err := unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		variables := []variable{}
+		err := s.onScript(func() {
+			if args.VariablesReference < 1 || args.VariablesReference > len(s.references) {
+				return
+			}
+			ref := s.references[args.VariablesReference-1]
+			scopes := s.debugger.Scopes(ref.frame)
+			if ref.scope >= len(scopes) {
+				return
+			}
+			for _, v := range scopes[ref.scope].Variables {
+				variables = append(variables, variable{Name: v.Name, Value: v.Value})
+			}
+		})
+		return map[string]any{"variables": variables}, err
+
+	case "evaluate":
+		var args struct {
+			Expression string `json:"expression"`
+			FrameID    int    `json:"frameId"`
+		}
+		if err := unmarshal(req.Arguments, &args); err != nil {
+			return nil, err
+		}
+		var result string
+		var evalErr error
+		err := s.onScript(func() {
+			value, err := s.debugger.Evaluate(max(args.FrameID-1, 0), args.Expression)
+			if err != nil {
+				evalErr = errors.New(lox.FormatError(err, "<expression>", args.Expression))
+				return
+			}
+			result = "nil"
+			if value != nil {
+				result = fmt.Sprint(value)
+			}
+		})
+		return map[string]any{"result": result, "variablesReference": 0}, errors.Join(err, evalErr)
+
+	case "terminate":
+		s.afterReply = s.stop
+		return nil, nil
+	case "disconnect":
+		return nil, nil
+	}
+
+	return nil, errors.New("Unknown command " + req.Command + ".")
+}
+
+// missing arguments are the same as empty ones
+func unmarshal(raw json.RawMessage, args any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, args)
+}
+
+func sourceOf(file string) source {
+	if absolute, err := filepath.Abs(file); err == nil {
+		file = absolute
+	}
+	return source{filepath.Base(file), file}
+}
+
+// runs the launched script once the client is done configuring it
+func (s *server) start() {
+	if !s.launched || !s.configured || s.started {
+		return
+	}
+	s.started = true
+
+	go func() {
+		defer close(s.done)
+		defer s.interpreter.Close()
+
+		err := s.interpreter.RunFile(s.program)
+		if err != nil && err != lox.ErrStopped {
+			s.event("output", outputEvent{"stderr", lox.FormatError(err, s.program, s.source) + "\n"})
+		}
+		s.event("exited", exitedEvent{lox.ExitCode(err)})
+		s.event("terminated", nil)
+	}()
+}
+
+// stops the script and waits until it has finished
+func (s *server) stop() {
+	if !s.started {
+		return
+	}
+	select {
+	case <-s.stopping:
+	default:
+		s.debugger.Stop()
+		close(s.stopping)
+	}
+	<-s.done
+}
+
+// called on the goroutine of the script every time it pauses
+func (s *server) pausedAt(reason lox.StopReason) {
+	if s.noDebug || reason == lox.StopEntry && !s.stopOnEntry {
+		s.debugger.Continue()
+		return
+	}
+
+	s.references = nil
+	s.paused.Store(true)
+	s.event("stopped", stoppedEvent{string(reason), threadID, true})
+
+	for {
+		select {
+		case work := <-s.work:
+			if work() {
+				return
+			}
+		case <-s.stopping:
+			return
+		}
+	}
+}
+
+// runs the function on the goroutine of the paused script and waits for it
+func (s *server) onScript(work func()) error {
+	if !s.paused.Load() {
+		return errNotPaused
+	}
+
+	done := make(chan struct{})
+	select {
+	case s.work <- func() bool { work(); close(done); return false }:
+		<-done
+		return nil
+	case <-s.stopping:
+		return errNotPaused
+	}
+}
+
+// resumes the paused script once the response is sent, after telling the
+// debugger how to go on
+func (s *server) resume(step func()) error {
+	if !s.paused.Load() {
+		return errNotPaused
+	}
+
+	s.paused.Store(false)
+	s.afterReply = func() {
+		select {
+		case s.work <- func() bool { step(); return true }:
+		case <-s.stopping:
+		}
+	}
+	return nil
+}
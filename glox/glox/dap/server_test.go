@@ -0,0 +1,194 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+const script = `fun add(a, b) {
+    var sum = a + b;
+    return sum;
+}
+var x = 1;
+var y = add(x, 2);
+print y;
+`
+
+// client talks to a server running on its own goroutine
+type client struct {
+	t   *testing.T
+	in  *io.PipeWriter
+	out *textproto.Reader
+	seq int
+
+	// what the script printed so far
+	output string
+}
+
+func newClient(t *testing.T) (*client, chan error) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(inReader, outWriter)
+		outWriter.Close()
+	}()
+
+	return &client{t: t, in: inWriter, out: textproto.NewReader(bufio.NewReader(outReader))}, served
+}
+
+func (c *client) send(command string, arguments any) {
+	c.seq++
+	body, _ := json.Marshal(map[string]any{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// reads the next message, the output events before it are collected
+func (c *client) next() map[string]any {
+	c.t.Helper()
+	for {
+		header, err := c.out.ReadMIMEHeader()
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(c.out.R, body); err != nil {
+			c.t.Fatal(err)
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(body, &msg); err != nil {
+			c.t.Fatal(err)
+		}
+
+		if msg["event"] != "output" {
+			return msg
+		}
+		c.output += msg["body"].(map[string]any)["output"].(string)
+	}
+}
+
+// reads the next message, which must be the named event or the successful
+// response to the named command, and returns its body
+func (c *client) expect(kind string, name string) map[string]any {
+	c.t.Helper()
+	msg := c.next()
+	if msg["type"] != kind || msg["command"] != name && msg["event"] != name {
+		c.t.Fatalf("expected %s %s, got %v", kind, name, msg)
+	}
+	if kind == "response" && msg["success"] != true {
+		c.t.Fatalf("%s failed: %v", name, msg["message"])
+	}
+	body, _ := msg["body"].(map[string]any)
+	return body
+}
+
+func (c *client) request(command string, arguments any) map[string]any {
+	c.t.Helper()
+	c.send(command, arguments)
+	return c.expect("response", command)
+}
+
+func TestServe(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "add.lox")
+	if err := os.WriteFile(program, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, served := newClient(t)
+	c.request("initialize", map[string]any{"adapterID": "glox"})
+	c.expect("event", "initialized")
+	c.request("launch", map[string]any{"program": program})
+	c.request("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": program},
+		"breakpoints": []any{map[string]any{"line": 3}},
+	})
+	c.request("configurationDone", nil)
+
+	if stopped := c.expect("event", "stopped"); stopped["reason"] != "breakpoint" {
+		t.Errorf("expected to stop at the breakpoint, got %v", stopped)
+	}
+
+	frames := c.request("stackTrace", map[string]any{"threadId": 1})["stackFrames"].([]any)
+	if fmt.Sprint(len(frames)) != "2" {
+		t.Fatalf("expected two frames, got %v", frames)
+	}
+	for i, expected := range []string{"add 3", "script 6"} {
+		frame := frames[i].(map[string]any)
+		if actual := fmt.Sprint(frame["name"], " ", frame["line"]); actual != expected {
+			t.Errorf("expected frame %s, got %s", expected, actual)
+		}
+	}
+
+	scopes := c.request("scopes", map[string]any{"frameId": 1})["scopes"].([]any)
+	if len(scopes) != 2 || scopes[0].(map[string]any)["name"] != "Locals" || scopes[1].(map[string]any)["name"] != "Globals" {
+		t.Fatalf("expected locals and globals, got %v", scopes)
+	}
+	locals := c.request("variables", map[string]any{"variablesReference": scopes[0].(map[string]any)["variablesReference"]})
+	if actual := fmt.Sprint(locals["variables"]); actual != "[map[name:a value:1 variablesReference:0] map[name:b value:2 variablesReference:0] map[name:sum value:3 variablesReference:0]]" {
+		t.Errorf("unexpected locals %s", actual)
+	}
+
+	if result := c.request("evaluate", map[string]any{"expression": "sum * 2", "frameId": 1}); result["result"] != "6" {
+		t.Errorf("expected 6, got %v", result)
+	}
+	c.send("evaluate", map[string]any{"expression": "missing", "frameId": 1})
+	if failed := c.next(); failed["success"] != false {
+		t.Errorf("expected evaluating an undefined variable to fail, got %v", failed)
+	}
+
+	c.request("next", map[string]any{"threadId": 1})
+	c.expect("event", "stopped")
+	frames = c.request("stackTrace", map[string]any{"threadId": 1})["stackFrames"].([]any)
+	if line := frames[0].(map[string]any)["line"]; line != 7.0 {
+		t.Errorf("expected to step out of add to line 7, got %v", line)
+	}
+
+	c.request("continue", map[string]any{"threadId": 1})
+	if exited := c.expect("event", "exited"); exited["exitCode"] != 0.0 {
+		t.Errorf("expected exit code 0, got %v", exited)
+	}
+	c.expect("event", "terminated")
+	if c.output != "3\n" {
+		t.Errorf("expected the script to print 3, got %q", c.output)
+	}
+
+	c.request("disconnect", nil)
+	if err := <-served; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDisconnectWhilePaused(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "add.lox")
+	if err := os.WriteFile(program, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, served := newClient(t)
+	c.request("initialize", nil)
+	c.expect("event", "initialized")
+	c.request("launch", map[string]any{"program": program, "stopOnEntry": true})
+	c.request("configurationDone", nil)
+	if stopped := c.expect("event", "stopped"); stopped["reason"] != "entry" {
+		t.Errorf("expected to stop on entry, got %v", stopped)
+	}
+
+	c.request("disconnect", nil)
+	if err := <-served; err != nil {
+		t.Fatal(err)
+	}
+	if c.output != "" {
+		t.Errorf("expected the script to be stopped before printing, got %q", c.output)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/daliborpovolny/lox/glox/glox/lox"
+)
+
+const debugHelp = `commands:
+  break, b [file:]line   pause before the statements of the line
+  clear [file:]line      remove the breakpoint
+  continue, c            run up to the next breakpoint
+  step, s                step into the next statement, in a call if there is one
+  next, n                step over calls to the next statement
+  out, o                 run until the current function returns
+  locals, l              print the variables of the selected frame
+  backtrace, bt          print the call frames
+  frame N                select frame N of the backtrace
+  print, p expr          evaluate the expression in the selected frame
+  quit, q                stop the script
+an empty line repeats the last command`
+
+// debugSession is the command prompt shown every time the debugged script
+// pauses, it reads the commands from the same input as the script
+type debugSession struct {
+	script   string
+	input    *bufio.Reader
+	debugger *lox.Debugger
+
+	// the frame that locals and print look at, 0 is where the script paused
+	frame int
+
+	// the command an empty line repeats
+	last string
+
+	// lines of the files shown so far
+	sources map[string][]string
+}
+
+// runs the script under the debugger, it pauses before the first statement
+func (l *Lox) debugFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	interpreter := lox.NewInterpreter()
+	defer interpreter.Close()
+	session := &debugSession{
+		script:  path,
+		input:   bufio.NewReader(os.Stdin),
+		sources: map[string][]string{path: strings.Split(string(source), "\n")},
+	}
+	interpreter.SetInput(session.input)
+	session.debugger = lox.NewDebugger(interpreter, session.paused)
+
+	fmt.Println(`glox debugger, type "help" for the commands`)
+	err = interpreter.RunFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, lox.FormatError(err, path, string(source)))
+		os.Exit(lox.ExitCode(err))
+	}
+	return nil
+}
+
+func (s *debugSession) paused(reason lox.StopReason) {
+	s.frame = 0
+	frame := s.debugger.Frames()[0]
+	fmt.Printf("paused (%s) in %s at %s:%d\n", reason, frame.Function, frame.File, frame.Line)
+	s.showLine(frame.File, frame.Line)
+
+	for {
+		fmt.Print("(debug) ")
+		line, err := s.input.ReadString('\n')
+		if err == io.EOF && line == "" {
+			fmt.Println()
+			os.Exit(0)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			line = s.last
+		}
+		s.last = line
+
+		if s.command(line) {
+			return
+		}
+	}
+}
+
+// runs one command, it returns true once the script should go on
+func (s *debugSession) command(line string) bool {
+	command, argument, _ := strings.Cut(line, " ")
+	argument = strings.TrimSpace(argument)
+
+	switch command {
+	case "":
+	case "continue", "c":
+		s.debugger.Continue()
+		return true
+	case "step", "s":
+		s.debugger.StepIn()
+		return true
+	case "next", "n":
+		s.debugger.StepOver()
+		return true
+	case "out", "o":
+		s.debugger.StepOut()
+		return true
+	case "break", "b", "clear":
+		file, line, err := s.location(argument)
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+
+		lines := s.debugger.Breakpoints(file)
+		if command == "clear" {
+			lines = slices.DeleteFunc(lines, func(l int) bool { return l == line })
+		} else {
+			lines = append(lines, line)
+		}
+		s.debugger.SetBreakpoints(file, lines)
+		fmt.Printf("breakpoints in %s: %v\n", file, s.debugger.Breakpoints(file))
+	case "locals", "l":
+		for _, scope := range s.debugger.Scopes(s.frame) {
+			fmt.Println(scope.Name + ":")
+			for _, variable := range scope.Variables {
+				fmt.Printf("  %s = %s\n", variable.Name, variable.Value)
+			}
+		}
+	case "backtrace", "bt":
+		for i, frame := range s.debugger.Frames() {
+			marker := " "
+			if i == s.frame {
+				marker = "*"
+			}
+			fmt.Printf("%s %d %s at %s:%d\n", marker, i, frame.Function, frame.File, frame.Line)
+		}
+	case "frame":
+		frames := s.debugger.Frames()
+		n, err := strconv.Atoi(argument)
+		if err != nil || n < 0 || n >= len(frames) {
+			fmt.Printf("expected a frame between 0 and %d\n", len(frames)-1)
+			break
+		}
+		s.frame = n
+		fmt.Printf("%s at %s:%d\n", frames[n].Function, frames[n].File, frames[n].Line)
+		s.showLine(frames[n].File, frames[n].Line)
+	case "print", "p":
+		value, err := s.debugger.Evaluate(s.frame, argument)
+		if err != nil {
+			fmt.Println(lox.FormatError(err, "<expression>", argument))
+			break
+		}
+		if value == nil {
+			fmt.Println("nil")
+		} else {
+			fmt.Println(value)
+		}
+	case "quit", "q":
+		os.Exit(0)
+	case "help", "h":
+		fmt.Println(debugHelp)
+	default:
+		fmt.Printf("unknown command %q, type \"help\" for the commands\n", command)
+	}
+	return false
+}
+
+// parses [file:]line, the file defaults to the script being debugged
+func (s *debugSession) location(argument string) (string, int, error) {
+	file := s.script
+	if i := strings.LastIndex(argument, ":"); i >= 0 {
+		file, argument = argument[:i], argument[i+1:]
+	}
+
+	line, err := strconv.Atoi(argument)
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("expected [file:]line, got %q", argument)
+	}
+	return file, line, nil
+}
+
+// prints the line of the file, read once the first time it is shown
+func (s *debugSession) showLine(file string, line int) {
+	lines, ok := s.sources[file]
+	if !ok {
+		source, _ := os.ReadFile(file)
+		lines = strings.Split(string(source), "\n")
+		s.sources[file] = lines
+	}
+
+	if line >= 1 && line <= len(lines) {
+		fmt.Printf("%4d | %s\n", line, lines[line-1])
+	}
+}
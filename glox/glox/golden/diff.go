@@ -0,0 +1,155 @@
+package golden
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lines of unchanged text shown around every change
+const diffContext = 3
+
+type diffLine struct {
+	// ' ' for a line in both texts, '-' for one only expected, '+' for one only in the actual text
+	kind byte
+	text string
+}
+
+// unifiedDiff shows how the actual text differs from the expected one the
+// way diff -u does, it is empty when they are equal
+func unifiedDiff(expectedName string, actualName string, expected string, actual string) string {
+	if expected == actual {
+		return ""
+	}
+
+	lines := diffLines(splitLines(expected), splitLines(actual))
+
+	// line numbers in both texts where each diff line is or would be
+	expectedLines := make([]int, len(lines)+1)
+	actualLines := make([]int, len(lines)+1)
+	expectedLines[0], actualLines[0] = 1, 1
+	for i, line := range lines {
+		expectedLines[i+1], actualLines[i+1] = expectedLines[i], actualLines[i]
+		if line.kind != '+' {
+			expectedLines[i+1]++
+		}
+		if line.kind != '-' {
+			actualLines[i+1]++
+		}
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", expectedName, actualName)
+
+	for i := 0; i < len(lines); i++ {
+		if lines[i].kind == ' ' {
+			continue
+		}
+
+		// the hunk goes on while the changes are close enough for their
+		// context lines to touch
+		last := i
+		for j := i + 1; j < len(lines) && j-last <= 2*diffContext+1; j++ {
+			if lines[j].kind != ' ' {
+				last = j
+			}
+		}
+		start := max(i-diffContext, 0)
+		end := min(last+1+diffContext, len(lines))
+
+		fmt.Fprintf(&diff, "@@ -%s +%s @@\n",
+			hunkRange(expectedLines[start], expectedLines[end]-expectedLines[start]),
+			hunkRange(actualLines[start], actualLines[end]-actualLines[start]))
+
+		for _, line := range lines[start:end] {
+			diff.WriteByte(line.kind)
+			if text, ok := strings.CutSuffix(line.text, "\n"); ok {
+				diff.WriteString(text + "\n")
+			} else {
+				diff.WriteString(text + "\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end - 1
+	}
+
+	return diff.String()
+}
+
+func hunkRange(start int, count int) string {
+	switch count {
+	case 0:
+		// an empty range names the line before it
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// the lines of the text, each with its line break
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines pairs up the longest common subsequence of the lines, whatever
+// is not part of it was removed or added
+func diffLines(expected []string, actual []string) []diffLine {
+	// the common start and end are left out of the quadratic part
+	prefix := 0
+	for prefix < len(expected) && prefix < len(actual) && expected[prefix] == actual[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(expected)-prefix && suffix < len(actual)-prefix &&
+		expected[len(expected)-1-suffix] == actual[len(actual)-1-suffix] {
+		suffix++
+	}
+
+	a := expected[prefix : len(expected)-suffix]
+	b := actual[prefix : len(actual)-suffix]
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(expected)+len(actual))
+	for _, line := range expected[:prefix] {
+		lines = append(lines, diffLine{' ', line})
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+
+	for _, line := range expected[len(expected)-suffix:] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	return lines
+}
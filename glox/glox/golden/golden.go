@@ -0,0 +1,243 @@
+// Package golden runs the Lox scripts of a directory and compares what they
+// do with the golden files next to them:
+//
+//	name.lox   the script
+//	name.out   what it prints to standard output
+//	name.err   what it prints to standard error, nothing when missing
+//	name.code  its exit status, 0 when missing
+//
+// every script runs on the tree-walking interpreter and on the VM and both
+// must match the same files
+package golden
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daliborpovolny/lox/glox/glox/lox"
+)
+
+type backend struct {
+	// added to the name of the results, empty for the interpreter
+	suffix string
+
+	new func() lox.Engine
+}
+
+var backends = []backend{
+	{"", func() lox.Engine { return lox.NewInterpreter() }},
+	{" (vm)", func() lox.Engine { return lox.NewVM() }},
+}
+
+// how long a script may run before it counts as hanging, it is left running
+// in the background then as an engine can not be stopped from the outside
+var timeout = 10 * time.Second
+
+// Result is the outcome of one script on one backend
+type Result struct {
+	// the name of the script without .lox, with " (vm)" after it for the VM
+	Name string
+
+	// unified diffs of the golden files that did not match, empty when the
+	// script did what they say
+	Diff string
+
+	// set when the golden files could not be read or written, or when the
+	// script panicked or did not finish in time
+	Err error
+}
+
+func (r Result) Passed() bool {
+	return r.Diff == "" && r.Err == nil
+}
+
+// Discover lists the scripts in the directory sorted by name, scripts in its
+// subdirectories are modules imported by the tests and not tests themselves
+func Discover(dir string) ([]string, error) {
+	scripts, err := filepath.Glob(filepath.Join(dir, "*.lox"))
+	if err == nil && len(scripts) == 0 {
+		err = errors.New("no tests in " + dir)
+	}
+	return scripts, err
+}
+
+// Run runs every script of the directory on every backend, the scripts run in
+// parallel and the results are in the order of Discover, with update the
+// golden files are first rewritten with what the script did on the interpreter
+func Run(dir string, update bool) ([]Result, error) {
+	scripts, err := Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]Result, len(scripts))
+	workers := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, script := range scripts {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer wg.Done()
+			results[i] = RunScript(script, update)
+			<-workers
+		}()
+	}
+	wg.Wait()
+
+	var all []Result
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	return all, nil
+}
+
+// RunScript runs one script on every backend and compares it with its golden
+// files, with update the files are first rewritten from the interpreter
+func RunScript(path string, update bool) []Result {
+	name := strings.TrimSuffix(filepath.Base(path), ".lox")
+	results := make([]Result, 0, len(backends))
+
+	for i, backend := range backends {
+		result := Result{Name: name + backend.suffix}
+		actual, err := run(backend.new(), path)
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		if update && i == 0 {
+			if err := actual.write(path); err != nil {
+				result.Err = err
+				results = append(results, result)
+				continue
+			}
+		}
+
+		expected, err := readOutcome(path)
+		if err != nil {
+			result.Err = err
+		} else {
+			result.Diff = expected.diff(actual, name, backend.suffix)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// outcome is what a script did, what its golden files hold
+type outcome struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// runs the script like the glox command would, on its own goroutine so that
+// a panic or a script that never ends fails only its own test
+func run(engine lox.Engine, path string) (outcome, error) {
+	type finished struct {
+		outcome outcome
+		err     error
+	}
+	done := make(chan finished, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- finished{err: fmt.Errorf("panicked: %v\n%s", r, debug.Stack())}
+			}
+		}()
+
+		var stdout, stderr strings.Builder
+		engine.SetOutput(&stdout)
+		engine.SetInput(strings.NewReader(""))
+		code := lox.RunScript(engine, path, &stderr)
+
+		// paths are relative to the directory of the tests, so that the golden
+		// files do not depend on where the tests run from
+		dir := filepath.Dir(path) + string(filepath.Separator)
+		done <- finished{outcome: outcome{stdout.String(), strings.ReplaceAll(stderr.String(), dir, ""), code}}
+	}()
+
+	select {
+	case result := <-done:
+		return result.outcome, result.err
+	case <-time.After(timeout):
+		return outcome{}, fmt.Errorf("did not finish within %s", timeout)
+	}
+}
+
+func goldenFile(path string, extension string) string {
+	return strings.TrimSuffix(path, ".lox") + extension
+}
+
+func readOutcome(path string) (outcome, error) {
+	read := func(extension string) (string, error) {
+		bytes, err := os.ReadFile(goldenFile(path, extension))
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return string(bytes), err
+	}
+
+	var result outcome
+	var err error
+	if result.stdout, err = read(".out"); err != nil {
+		return result, err
+	}
+	if result.stderr, err = read(".err"); err != nil {
+		return result, err
+	}
+
+	code, err := read(".code")
+	if err != nil || code == "" {
+		return result, err
+	}
+	if result.code, err = strconv.Atoi(strings.TrimSpace(code)); err != nil {
+		return result, fmt.Errorf("%s: %w", goldenFile(path, ".code"), err)
+	}
+	return result, nil
+}
+
+// writes the golden files, the ones that would hold nothing are removed
+func (o outcome) write(path string) error {
+	write := func(extension string, content string, keep bool) error {
+		file := goldenFile(path, extension)
+		if keep {
+			return os.WriteFile(file, []byte(content), 0644)
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	return errors.Join(
+		write(".out", o.stdout, true),
+		write(".err", o.stderr, o.stderr != ""),
+		write(".code", fmt.Sprintln(o.code), o.code != 0),
+	)
+}
+
+func (o outcome) diff(actual outcome, name string, suffix string) string {
+	var diffs strings.Builder
+	compare := func(extension string, expected string, actual string) {
+		file := name + extension
+		diffs.WriteString(unifiedDiff(file, file+suffix+" actual", expected, actual))
+	}
+
+	compare(".out", o.stdout, actual.stdout)
+	compare(".err", o.stderr, actual.stderr)
+	compare(".code", fmt.Sprintln(o.code), fmt.Sprintln(actual.code))
+	return diffs.String()
+}
@@ -0,0 +1,104 @@
+package golden
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daliborpovolny/lox/glox/glox/lox"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with what the scripts do")
+
+func TestGolden(t *testing.T) {
+	scripts, err := Discover("../../tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, script := range scripts {
+		t.Run(strings.TrimSuffix(filepath.Base(script), ".lox"), func(t *testing.T) {
+			t.Parallel()
+			for _, result := range RunScript(script, *update) {
+				if result.Err != nil {
+					t.Errorf("%s: %v", result.Name, result.Err)
+				} else if !result.Passed() {
+					t.Errorf("%s:\n%s", result.Name, result.Diff)
+				}
+			}
+		})
+	}
+}
+
+func TestRunScriptFailsAlone(t *testing.T) {
+	defer func(saved []backend, savedTimeout time.Duration) {
+		backends, timeout = saved, savedTimeout
+	}(backends, timeout)
+
+	timeout = 100 * time.Millisecond
+	backends = []backend{{"", func() lox.Engine {
+		interpreter := lox.NewInterpreter()
+		interpreter.DefineNative("crash", 0, func(arguments []lox.Value) (lox.Value, error) {
+			panic("boom")
+		})
+		return interpreter
+	}}}
+
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"crash.lox": "crash();",
+		"hang.lox":  "while (true) {}",
+	}
+	for name, source := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := Run(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected a result for every script, got %v", results)
+	}
+	if err := results[0].Err; err == nil || !strings.HasPrefix(err.Error(), "panicked: boom") {
+		t.Errorf("expected crash to fail with its panic, got %v", err)
+	}
+	if err := results[1].Err; err == nil || !strings.HasPrefix(err.Error(), "did not finish") {
+		t.Errorf("expected hang to time out, got %v", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	expected := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	actual := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl"
+
+	diff := unifiedDiff("x.out", "x.out actual", expected, actual)
+	want := `--- x.out
++++ x.out actual
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,3 +9,4 @@
+ i
+ j
+ k
++l
+\ No newline at end of file
+`
+	if diff != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, diff)
+	}
+
+	if diff := unifiedDiff("x.out", "x.out actual", expected, expected); diff != "" {
+		t.Errorf("expected no diff, got\n%s", diff)
+	}
+}
@@ -0,0 +1,185 @@
+package lox
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Analysis is what an editor needs to know about a program without running
+// it, the errors in it and where its variables are declared and used
+type Analysis struct {
+	// every error the scanner, the parser and the resolver found, sorted by position
+	Diagnostics []*CompileError
+
+	// the variables, functions, classes and parameters in order of declaration
+	Symbols []*Symbol
+}
+
+type SymbolKind int
+
+const (
+	SymbolVariable SymbolKind = iota
+	SymbolFunction
+	SymbolClass
+	SymbolParameter
+	SymbolModule
+)
+
+// Symbol is a name declared in a program, the statements that failed to
+// parse are left out so the uses in them are missing
+type Symbol struct {
+	Name string
+	Kind SymbolKind
+
+	// the declaration as it would be written, like "fun add(a, b)"
+	Signature string
+
+	Declaration Location
+
+	// every use of the symbol, globals declared again are listed here as well
+	References []Location
+}
+
+// Location is the position of a name in the source, lines and columns count
+// from 1 and columns and lengths count characters
+type Location struct {
+	Line   int
+	Column int
+	Length int
+}
+
+// Contains tells if the character at the position is part of the name
+func (l Location) Contains(line int, column int) bool {
+	return line == l.Line && column >= l.Column && column < l.Column+l.Length
+}
+
+func tokenLocation(token Token) Location {
+	return Location{token.line, token.column, utf8.RuneCountInString(token.lexeme)}
+}
+
+// Analyze scans, parses and resolves the source, unlike running it the
+// analysis goes on after errors and covers the statements that did parse
+func Analyze(source string) *Analysis {
+	scanner := NewScanner(source)
+	parser := NewParser(scanner.scanTokens())
+	statements := parser.Parse()
+
+	symbols := newSymbolTable()
+	resolver := NewResolver()
+	resolver.symbols = symbols
+	resolver.Resolve(statements)
+	symbols.bindGlobals()
+
+	diagnostics := slices.Concat(scanner.errors, parser.errors, resolver.errors)
+	diagnostics.sort()
+
+	return &Analysis{Diagnostics: diagnostics, Symbols: symbols.symbols}
+}
+
+// SymbolAt returns the symbol declared or used at the position, nil when
+// there is no name there or it is not declared anywhere
+func (a *Analysis) SymbolAt(line int, column int) *Symbol {
+	for _, symbol := range a.Symbols {
+		if symbol.Declaration.Contains(line, column) {
+			return symbol
+		}
+		for _, reference := range symbol.References {
+			if reference.Contains(line, column) {
+				return symbol
+			}
+		}
+	}
+	return nil
+}
+
+// symbolTable records the declarations and the uses of names for Analyze
+// while the Resolver walks the program
+type symbolTable struct {
+	symbols []*Symbol
+
+	// follows the scopes of the resolver
+	scopes  []map[string]*Symbol
+	globals map[string]*Symbol
+
+	// uses that were not found in any scope, bound once all globals are known
+	// as functions may use globals that are declared after them
+	unresolved []Token
+}
+
+func newSymbolTable() *symbolTable {
+	return &symbolTable{globals: make(map[string]*Symbol, 16)}
+}
+
+func (t *symbolTable) beginScope() {
+	t.scopes = append(t.scopes, make(map[string]*Symbol, 8))
+}
+
+func (t *symbolTable) endScope() {
+	t.scopes = t.scopes[:len(t.scopes)-1]
+}
+
+func (t *symbolTable) declare(name string, at Location, kind SymbolKind, signature string) {
+	if len(t.scopes) == 0 {
+		if global, ok := t.globals[name]; ok {
+			global.References = append(global.References, at)
+			return
+		}
+	}
+
+	symbol := &Symbol{
+		Name:        name,
+		Kind:        kind,
+		Signature:   signature,
+		Declaration: at,
+	}
+	t.symbols = append(t.symbols, symbol)
+
+	if len(t.scopes) == 0 {
+		t.globals[name] = symbol
+	} else {
+		t.scopes[len(t.scopes)-1][name] = symbol
+	}
+}
+
+// records a use of the name the resolver found in the scope at depth,
+// a negative depth stands for a global
+func (t *symbolTable) use(name Token, depth int) {
+	if depth < 0 {
+		t.unresolved = append(t.unresolved, name)
+		return
+	}
+
+	// "this" is in scope without being declared
+	if symbol, ok := t.scopes[depth][name.lexeme]; ok {
+		symbol.References = append(symbol.References, tokenLocation(name))
+	}
+}
+
+func (t *symbolTable) bindGlobals() {
+	for _, name := range t.unresolved {
+		if symbol, ok := t.globals[name.lexeme]; ok {
+			symbol.References = append(symbol.References, tokenLocation(name))
+		}
+	}
+	t.unresolved = nil
+}
+
+// the declaration of a function as it would be written
+func functionSignature(keyword string, function *Function) string {
+	params := make([]string, len(function.params))
+	for i, param := range function.params {
+		params[i] = param.lexeme
+	}
+	return keyword + function.name.lexeme + "(" + strings.Join(params, ", ") + ")"
+}
+
+// a class is called with the parameters of its initializer
+func classSignature(class *Class) string {
+	for _, method := range class.methods {
+		if method.name.lexeme == "init" {
+			return functionSignature("class ", &Function{name: class.name, params: method.params})
+		}
+	}
+	return "class " + class.name.lexeme
+}
@@ -0,0 +1,52 @@
+package lox
+
+import "testing"
+
+func TestAnalyze(t *testing.T) {
+	source := `fun add(a, b) {
+    return a + b;
+}
+var total = add(1, 2);
+{
+    var total = "local";
+    print total;
+}
+print total + add(3, 4);
+var broken = ;
+`
+	analysis := Analyze(source)
+
+	if len(analysis.Diagnostics) != 1 || analysis.Diagnostics[0].Line != 10 {
+		t.Fatalf("expected one error on line 10, got %v", analysis.Diagnostics)
+	}
+
+	add := analysis.SymbolAt(9, 15)
+	if add == nil || add.Signature != "fun add(a, b)" {
+		t.Fatalf("expected add at 9:15, got %+v", add)
+	}
+	if add.Declaration != (Location{1, 5, 3}) {
+		t.Errorf("expected add declared at 1:5, got %+v", add.Declaration)
+	}
+	if len(add.References) != 2 {
+		t.Errorf("expected 2 uses of add, got %+v", add.References)
+	}
+
+	// the local total shadows the global one in the block only
+	global := analysis.SymbolAt(9, 7)
+	local := analysis.SymbolAt(7, 11)
+	if global == nil || local == nil || global == local {
+		t.Fatalf("expected two different totals, got %+v and %+v", global, local)
+	}
+	if global.Declaration.Line != 4 || local.Declaration.Line != 6 {
+		t.Errorf("expected totals declared on lines 4 and 6, got %+v and %+v", global, local)
+	}
+
+	param := analysis.SymbolAt(2, 12)
+	if param == nil || param.Kind != SymbolParameter || param.Name != "a" {
+		t.Errorf("expected the parameter a at 2:12, got %+v", param)
+	}
+
+	if symbol := analysis.SymbolAt(2, 5); symbol != nil {
+		t.Errorf("expected no symbol on return, got %+v", symbol)
+	}
+}
@@ -0,0 +1,230 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+type AstPrinter struct{}
+
+func (a AstPrinter) Print(statements []Stmt) string {
+	var builder strings.Builder
+	for _, stmt := range statements {
+		builder.WriteString(stmt.Accept(a).(string))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (a AstPrinter) VisitBlockStmt(stmt *Block) any {
+	var output strings.Builder
+
+	output.WriteString("block\n")
+	output.WriteString(a.Print(stmt.statements))
+	output.WriteString("endblock\n")
+
+	return output.String()
+}
+
+func (a AstPrinter) VisitExpressionStmt(stmt *Expression) any {
+	return a.parenthesize("expression", stmt.expression)
+}
+
+func (a AstPrinter) VisitPrintStmt(stmt *Print) any {
+	return a.parenthesize("print", stmt.expression)
+}
+
+func (a AstPrinter) VisitReturnStmt(stmt *Return) any {
+	return a.parenthesize("return", stmt.value)
+}
+
+func (a AstPrinter) VisitImportStmt(stmt *Import) any {
+	return "(import " + stmt.name.lexeme + " " + stmt.path.lexeme + ")"
+}
+
+func (a AstPrinter) VisitThrowStmt(stmt *Throw) any {
+	return a.parenthesize("throw", stmt.value)
+}
+
+func (a AstPrinter) VisitTryStmt(stmt *Try) any {
+	var output strings.Builder
+
+	output.WriteString("try\n")
+	output.WriteString(a.Print(stmt.body))
+	if stmt.hasCatch {
+		output.WriteString("catch " + stmt.catchName.lexeme + "\n")
+		output.WriteString(a.Print(stmt.catchBody))
+	}
+	if stmt.finallyBody != nil {
+		output.WriteString("finally\n")
+		output.WriteString(a.Print(stmt.finallyBody))
+	}
+	output.WriteString("endtry\n")
+
+	return output.String()
+}
+
+func (a AstPrinter) VisitYieldStmt(stmt *Yield) any {
+	return a.parenthesize("yield", stmt.value)
+}
+
+func (a AstPrinter) VisitVarStmt(stmt *Var) any {
+	return a.parenthesize("var "+stmt.name.lexeme, stmt.initializer)
+}
+
+func (a AstPrinter) VisitFunctionStmt(stmt *Function) any {
+	return a.parenthesize("fun")
+}
+
+func (a AstPrinter) VisitClassStmt(stmt *Class) any {
+	var output strings.Builder
+
+	output.WriteString("(class " + stmt.name.lexeme)
+	for _, method := range stmt.methods {
+		output.WriteString(" " + method.Accept(a).(string))
+	}
+	output.WriteString(")")
+
+	return output.String()
+}
+
+func (a AstPrinter) VisitIfStmt(stmt *If) any {
+	var output strings.Builder
+
+	output.WriteString("if " + stmt.condition.Accept(a).(string))
+	output.WriteString(" then" + stmt.thenBranch.Accept(a).(string))
+	if stmt.elseBranch != nil {
+	}
+	output.WriteString(" else" + stmt.elseBranch.Accept(a).(string))
+
+	return output.String()
+}
+
+func (a AstPrinter) VisitWhileStmt(stmt *While) any {
+	var output strings.Builder
+
+	output.WriteString("while " + stmt.condition.Accept(a).(string))
+	output.WriteString("then" + stmt.body.Accept(a).(string))
+	if stmt.increment != nil {
+		output.WriteString(" increment " + stmt.increment.Accept(a).(string))
+	}
+
+	return output.String()
+}
+
+func (a AstPrinter) VisitBreakStmt(stmt *Break) any {
+	return "(break)"
+}
+
+func (a AstPrinter) VisitContinueStmt(stmt *Continue) any {
+	return "(continue)"
+}
+
+func (a AstPrinter) VisitVariableExpr(expr *Variable) any {
+	return expr.name.lexeme
+}
+
+func (a AstPrinter) VisitAssignExpr(expr *Assign) any {
+	return a.parenthesize("assign "+expr.name.lexeme, expr.value)
+}
+
+func (a AstPrinter) VisitCommaExpr(expr *Comma) any {
+	return a.parenthesize("comma", expr.exprs...)
+}
+
+func (a AstPrinter) VisitTernaryExpr(expr *Ternary) any {
+	return a.parenthesize("ternary", expr.condition, expr.outcome1, expr.outcome2)
+}
+
+func (a AstPrinter) VisitLogicalExpr(expr *Logical) any {
+	return a.parenthesize("logical "+expr.operator.lexeme, expr.left, expr.right)
+}
+
+func (a AstPrinter) VisitBinaryExpr(expr *Binary) any {
+	return a.parenthesize(expr.operator.lexeme, expr.left, expr.right)
+}
+
+func (a AstPrinter) VisitCallExpr(expr *Call) any {
+	return a.parenthesize("call", expr.arguments...)
+}
+
+func (a AstPrinter) VisitGetExpr(expr *Get) any {
+	return a.parenthesize("get "+expr.name.lexeme, expr.object)
+}
+
+func (a AstPrinter) VisitSetExpr(expr *Set) any {
+	return a.parenthesize("set "+expr.name.lexeme, expr.object, expr.value)
+}
+
+func (a AstPrinter) VisitInterpolationExpr(expr *Interpolation) any {
+	return a.parenthesize("interpolate", expr.parts...)
+}
+
+func (a AstPrinter) VisitLambdaExpr(expr *Lambda) any {
+	params := make([]string, len(expr.function.params))
+	for i, param := range expr.function.params {
+		params[i] = param.lexeme
+	}
+
+	var output strings.Builder
+	output.WriteString("(lambda (" + strings.Join(params, " ") + ")")
+	for _, stmt := range expr.function.body {
+		output.WriteString(" " + stmt.Accept(a).(string))
+	}
+	output.WriteString(")")
+
+	return output.String()
+}
+
+func (a AstPrinter) VisitListExpr(expr *List) any {
+	return a.parenthesize("list", expr.elements...)
+}
+
+func (a AstPrinter) VisitMapExpr(expr *Map) any {
+	entries := make([]Expr, 0, 2*len(expr.keys))
+	for i := range expr.keys {
+		entries = append(entries, expr.keys[i], expr.values[i])
+	}
+	return a.parenthesize("map", entries...)
+}
+
+func (a AstPrinter) VisitIndexExpr(expr *Index) any {
+	return a.parenthesize("index", expr.object, expr.index)
+}
+
+func (a AstPrinter) VisitSetIndexExpr(expr *SetIndex) any {
+	return a.parenthesize("set index", expr.object, expr.index, expr.value)
+}
+
+func (a AstPrinter) VisitThisExpr(expr *This) any {
+	return "this"
+}
+
+func (a AstPrinter) VisitGroupingExpr(expr *Grouping) any {
+	return a.parenthesize("group", expr.expression)
+}
+
+func (a AstPrinter) VisitLiteralExpr(expr *Literal) any {
+	if expr.value == nil {
+		return "nil"
+	}
+	return fmt.Sprint(expr.value)
+}
+
+func (a AstPrinter) VisitUnaryExpr(expr *Unary) any {
+	return a.parenthesize(expr.operator.lexeme, expr.right)
+}
+
+func (a AstPrinter) parenthesize(name string, exprs ...Expr) string {
+	var s strings.Builder
+	s.WriteString("(")
+	s.WriteString(name)
+	for _, expr := range exprs {
+		if expr != nil {
+			s.WriteString(" ")
+			s.WriteString(expr.Accept(a).(string))
+		}
+	}
+	s.WriteString(")")
+	return s.String()
+}
@@ -0,0 +1,16 @@
+package lox
+
+import "testing"
+
+func TestPrintLambda(t *testing.T) {
+	statements, err := parse("(a, b) => a * b; fun (x) { return -x; };", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "(expression (lambda (a b) (return (* a b))))\n" +
+		"(expression (lambda (x) (return (- x))))\n"
+	if printed := (AstPrinter{}).Print(statements); printed != expected {
+		t.Errorf("expected %q, got %q", expected, printed)
+	}
+}
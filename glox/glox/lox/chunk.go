@@ -0,0 +1,210 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+type OpCode byte
+
+const (
+	OP_CONSTANT OpCode = iota // [index:2] pushes a constant
+	OP_NIL
+	OP_TRUE
+	OP_FALSE
+	OP_UNINITIALIZED // pushes the value of a variable declared without initializer
+	OP_POP
+
+	OP_GET_LOCAL         // [slot:1]
+	OP_SET_LOCAL         // [slot:1]
+	OP_GET_GLOBAL        // [name:2]
+	OP_DEFINE_GLOBAL     // [name:2]
+	OP_SET_GLOBAL        // [name:2]
+	OP_GET_UPVALUE       // [index:1]
+	OP_SET_UPVALUE       // [index:1]
+	OP_CHECK_INITIALIZED // [name:2] fails if the value on top was never assigned
+
+	OP_GET_PROPERTY // [name:2]
+	OP_SET_PROPERTY // [name:2]
+
+	OP_INTERPOLATE // [count:2] replaces the parts on top with their text joined
+	OP_LIST        // [count:2] replaces the elements on top with a list of them
+	OP_MAP         // [count:2] replaces the keys and values on top with a map of them
+	OP_GET_INDEX   // pops the list and the index, pushes the element
+	OP_SET_INDEX   // pops the list, the index and the value, pushes the value
+
+	OP_EQUAL
+	OP_NOT_EQUAL
+	OP_GREATER
+	OP_GREATER_EQUAL
+	OP_LESS
+	OP_LESS_EQUAL
+	OP_ADD
+	OP_SUBTRACT
+	OP_MULTIPLY
+	OP_DIVIDE
+	OP_MODULO
+	OP_NOT
+	OP_NEGATE
+
+	OP_PRINT
+	OP_JUMP          // [offset:2]
+	OP_JUMP_IF_FALSE // [offset:2] leaves the condition on the stack
+	OP_LOOP          // [offset:2] jumps backwards
+
+	OP_CALL          // [argCount:1]
+	OP_CLOSURE       // [function:2] followed by [isLocal:1, index:1] per upvalue
+	OP_CLOSE_UPVALUE // closes the upvalue of the local on top and pops it
+	OP_RETURN
+	OP_YIELD
+
+	OP_THROW   // raises the value on top as an error
+	OP_TRY     // [offset:2] errors raised until the matching OP_END_TRY jump forward to the handler
+	OP_END_TRY // drops the handler of the innermost OP_TRY
+	OP_CATCH   // replaces the error the handler got with the value a catch clause binds
+
+	OP_IMPORT // [path:2] pushes the namespace of the module, runs the module first if needed
+
+	OP_CLASS  // [name:2]
+	OP_METHOD // [name:2] adds the closure on top to the class below it
+)
+
+var opCodeNames = [...]string{
+	OP_CONSTANT:          "OP_CONSTANT",
+	OP_NIL:               "OP_NIL",
+	OP_TRUE:              "OP_TRUE",
+	OP_FALSE:             "OP_FALSE",
+	OP_UNINITIALIZED:     "OP_UNINITIALIZED",
+	OP_POP:               "OP_POP",
+	OP_GET_LOCAL:         "OP_GET_LOCAL",
+	OP_SET_LOCAL:         "OP_SET_LOCAL",
+	OP_GET_GLOBAL:        "OP_GET_GLOBAL",
+	OP_DEFINE_GLOBAL:     "OP_DEFINE_GLOBAL",
+	OP_SET_GLOBAL:        "OP_SET_GLOBAL",
+	OP_GET_UPVALUE:       "OP_GET_UPVALUE",
+	OP_SET_UPVALUE:       "OP_SET_UPVALUE",
+	OP_CHECK_INITIALIZED: "OP_CHECK_INITIALIZED",
+	OP_GET_PROPERTY:      "OP_GET_PROPERTY",
+	OP_SET_PROPERTY:      "OP_SET_PROPERTY",
+	OP_INTERPOLATE:       "OP_INTERPOLATE",
+	OP_LIST:              "OP_LIST",
+	OP_MAP:               "OP_MAP",
+	OP_GET_INDEX:         "OP_GET_INDEX",
+	OP_SET_INDEX:         "OP_SET_INDEX",
+	OP_EQUAL:             "OP_EQUAL",
+	OP_NOT_EQUAL:         "OP_NOT_EQUAL",
+	OP_GREATER:           "OP_GREATER",
+	OP_GREATER_EQUAL:     "OP_GREATER_EQUAL",
+	OP_LESS:              "OP_LESS",
+	OP_LESS_EQUAL:        "OP_LESS_EQUAL",
+	OP_ADD:               "OP_ADD",
+	OP_SUBTRACT:          "OP_SUBTRACT",
+	OP_MULTIPLY:          "OP_MULTIPLY",
+	OP_DIVIDE:            "OP_DIVIDE",
+	OP_MODULO:            "OP_MODULO",
+	OP_NOT:               "OP_NOT",
+	OP_NEGATE:            "OP_NEGATE",
+	OP_PRINT:             "OP_PRINT",
+	OP_JUMP:              "OP_JUMP",
+	OP_JUMP_IF_FALSE:     "OP_JUMP_IF_FALSE",
+	OP_LOOP:              "OP_LOOP",
+	OP_CALL:              "OP_CALL",
+	OP_CLOSURE:           "OP_CLOSURE",
+	OP_CLOSE_UPVALUE:     "OP_CLOSE_UPVALUE",
+	OP_RETURN:            "OP_RETURN",
+	OP_YIELD:             "OP_YIELD",
+	OP_THROW:             "OP_THROW",
+	OP_TRY:               "OP_TRY",
+	OP_END_TRY:           "OP_END_TRY",
+	OP_CATCH:             "OP_CATCH",
+	OP_IMPORT:            "OP_IMPORT",
+	OP_CLASS:             "OP_CLASS",
+	OP_METHOD:            "OP_METHOD",
+}
+
+func (op OpCode) String() string {
+	if int(op) < len(opCodeNames) && opCodeNames[op] != "" {
+		return opCodeNames[op]
+	}
+	return fmt.Sprintf("OP_UNKNOWN(%d)", op)
+}
+
+// Chunk is the compiled code of a single function
+type Chunk struct {
+	code      []byte
+	lines     []int    // source line of every byte in code
+	columns   []int    // source column of every byte in code
+	lexemes   []string // lexeme of the token of every byte in code, underlined in errors
+	constants []any
+}
+
+func (c *Chunk) write(b byte, token Token) {
+	c.code = append(c.code, b)
+	c.lines = append(c.lines, token.line)
+	c.columns = append(c.columns, token.column)
+	c.lexemes = append(c.lexemes, token.lexeme)
+}
+
+func (c *Chunk) addConstant(value any) int {
+	c.constants = append(c.constants, value)
+	return len(c.constants) - 1
+}
+
+func (c *Chunk) readShort(offset int) int {
+	return int(c.code[offset])<<8 | int(c.code[offset+1])
+}
+
+// returns a human readable listing of the chunk, used when debugging the compiler
+func (c *Chunk) disassemble(name string) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "== %s ==\n", name)
+
+	for offset := 0; offset < len(c.code); {
+		offset = c.disassembleInstruction(&out, offset)
+	}
+
+	return out.String()
+}
+
+func (c *Chunk) disassembleInstruction(out *strings.Builder, offset int) int {
+	fmt.Fprintf(out, "%04d %4d ", offset, c.lines[offset])
+
+	op := OpCode(c.code[offset])
+	switch op {
+	case OP_CONSTANT, OP_GET_GLOBAL, OP_DEFINE_GLOBAL, OP_SET_GLOBAL, OP_CHECK_INITIALIZED,
+		OP_GET_PROPERTY, OP_SET_PROPERTY, OP_CLASS, OP_METHOD, OP_IMPORT:
+		index := c.readShort(offset + 1)
+		fmt.Fprintf(out, "%-20s %4d '%s'\n", op, index, stringify(c.constants[index]))
+		return offset + 3
+	case OP_GET_LOCAL, OP_SET_LOCAL, OP_GET_UPVALUE, OP_SET_UPVALUE, OP_CALL:
+		fmt.Fprintf(out, "%-20s %4d\n", op, c.code[offset+1])
+		return offset + 2
+	case OP_INTERPOLATE, OP_LIST, OP_MAP:
+		fmt.Fprintf(out, "%-20s %4d\n", op, c.readShort(offset+1))
+		return offset + 3
+	case OP_JUMP, OP_JUMP_IF_FALSE, OP_TRY:
+		fmt.Fprintf(out, "%-20s %4d -> %d\n", op, offset, offset+3+c.readShort(offset+1))
+		return offset + 3
+	case OP_LOOP:
+		fmt.Fprintf(out, "%-20s %4d -> %d\n", op, offset, offset+3-c.readShort(offset+1))
+		return offset + 3
+	case OP_CLOSURE:
+		index := c.readShort(offset + 1)
+		function := c.constants[index].(*vmFunction)
+		fmt.Fprintf(out, "%-20s %4d %s\n", op, index, function)
+
+		offset += 3
+		for range function.upvalueCount {
+			kind := "upvalue"
+			if c.code[offset] == 1 {
+				kind = "local"
+			}
+			fmt.Fprintf(out, "%04d    |                      %s %d\n", offset, kind, c.code[offset+1])
+			offset += 2
+		}
+		return offset
+	default:
+		fmt.Fprintf(out, "%s\n", op)
+		return offset + 1
+	}
+}
@@ -0,0 +1,902 @@
+package lox
+
+import "math"
+
+const maxLocals = math.MaxUint8 + 1
+
+// vmLocal is a local variable of the function being compiled, its index in
+// funcCompiler.locals is its stack slot relative to the frame
+type vmLocal struct {
+	name       string
+	depth      int
+	isCaptured bool
+
+	// declared without initializer, reads have to check it was assigned
+	maybeUninitialized bool
+}
+
+type vmUpvalueRef struct {
+	index   byte
+	isLocal bool
+
+	maybeUninitialized bool
+}
+
+type vmLoop struct {
+	scopeDepth    int
+	breakJumps    []int
+	continueJumps []int
+}
+
+// vmTry is a try statement whose body or catch clause is being compiled with
+// a handler active, leaving it early with return, break or continue has to drop its handler
+// and run its finally block on the way out
+type vmTry struct {
+	// number of loops around the try statement
+	loopDepth int
+
+	finallyBody []Stmt
+}
+
+// funcCompiler holds the state of one function while its body is compiled,
+// functions nested in it get their own funcCompiler linked through enclosing
+type funcCompiler struct {
+	enclosing *funcCompiler
+	function  *vmFunction
+	kind      functionType
+
+	locals     []vmLocal
+	upvalues   []vmUpvalueRef
+	scopeDepth int
+	loops      []*vmLoop
+	tries      []*vmTry
+
+	constants map[any]int
+}
+
+// Compiler turns the statements of a script into bytecode for the VM, it relies
+// on the Resolver having already reported the scoping errors
+type Compiler struct {
+	current *funcCompiler
+
+	// path of the file the statements come from
+	file string
+
+	// last token seen, used for line numbers and error reporting
+	token Token
+
+	errors CompileErrors
+}
+
+func NewCompiler(file string) *Compiler {
+	return &Compiler{file: file}
+}
+
+// compiles the statements into the function that runs the whole script, with
+// eval set the script returns the value of a final expression statement
+func (c *Compiler) Compile(statements []Stmt, eval bool) (*vmFunction, error) {
+	c.beginFunction("", functionNone)
+
+	for index, stmt := range statements {
+		if exprStmt, ok := stmt.(*Expression); ok && eval && index == len(statements)-1 {
+			c.compileExpr(exprStmt.expression)
+			c.emitOp(OP_RETURN)
+			continue
+		}
+		c.compileStmt(stmt)
+	}
+
+	function, _ := c.endFunction()
+	if len(c.errors) > 0 {
+		return nil, c.errors
+	}
+	return function, nil
+}
+
+func (c *Compiler) beginFunction(name string, kind functionType) {
+	fc := &funcCompiler{
+		enclosing: c.current,
+		function:  &vmFunction{name: name, file: c.file},
+		kind:      kind,
+		locals:    make([]vmLocal, 0, 8),
+		constants: make(map[any]int, 8),
+	}
+
+	// slot zero holds the callee, methods see it as this
+	slot := vmLocal{name: ""}
+	if kind == functionMethod || kind == functionInitializer {
+		slot.name = "this"
+	}
+	fc.locals = append(fc.locals, slot)
+
+	c.current = fc
+}
+
+func (c *Compiler) endFunction() (*vmFunction, []vmUpvalueRef) {
+	c.emitReturn()
+
+	fc := c.current
+	c.current = fc.enclosing
+	return fc.function, fc.upvalues
+}
+
+func (c *Compiler) compileStmt(stmt Stmt) {
+	stmt.Accept(c)
+}
+
+func (c *Compiler) compileExpr(expr Expr) {
+	expr.Accept(c)
+}
+
+func (c *Compiler) error(message string) {
+	c.errors = append(c.errors, newCompileError(c.token, message))
+}
+
+// emitting
+
+func (c *Compiler) chunk() *Chunk {
+	return &c.current.function.chunk
+}
+
+func (c *Compiler) emitByte(b byte) {
+	c.chunk().write(b, c.token)
+}
+
+func (c *Compiler) emitOp(op OpCode) {
+	c.emitByte(byte(op))
+}
+
+func (c *Compiler) emitOpByte(op OpCode, operand byte) {
+	c.emitOp(op)
+	c.emitByte(operand)
+}
+
+func (c *Compiler) emitOpShort(op OpCode, operand int) {
+	c.emitOp(op)
+	c.emitByte(byte(operand >> 8))
+	c.emitByte(byte(operand))
+}
+
+func (c *Compiler) emitReturn() {
+	if c.current.kind == functionInitializer {
+		c.emitOpByte(OP_GET_LOCAL, 0)
+	} else {
+		c.emitOp(OP_NIL)
+	}
+	c.emitOp(OP_RETURN)
+}
+
+func (c *Compiler) makeConstant(value any) int {
+	if index, ok := c.current.constants[value]; ok {
+		return index
+	}
+
+	index := c.chunk().addConstant(value)
+	if index > math.MaxUint16 {
+		c.error("Too many constants in one chunk.")
+		return 0
+	}
+
+	// functions are compiled once per declaration and never shared
+	if _, ok := value.(*vmFunction); !ok {
+		c.current.constants[value] = index
+	}
+	return index
+}
+
+func (c *Compiler) emitConstant(value any) {
+	c.emitOpShort(OP_CONSTANT, c.makeConstant(value))
+}
+
+// emits a jump with a placeholder offset, returns where to patch it
+func (c *Compiler) emitJump(op OpCode) int {
+	c.emitOpShort(op, 0xffff)
+	return len(c.chunk().code) - 2
+}
+
+// points the jump at the next instruction
+func (c *Compiler) patchJump(offset int) {
+	jump := len(c.chunk().code) - offset - 2
+	if jump > math.MaxUint16 {
+		c.error("Too much code to jump over.")
+	}
+
+	c.chunk().code[offset] = byte(jump >> 8)
+	c.chunk().code[offset+1] = byte(jump)
+}
+
+func (c *Compiler) emitLoop(loopStart int) {
+	offset := len(c.chunk().code) - loopStart + 3
+	if offset > math.MaxUint16 {
+		c.error("Loop body too large.")
+	}
+	c.emitOpShort(OP_LOOP, offset)
+}
+
+// scopes and variables
+
+func (c *Compiler) beginScope() {
+	c.current.scopeDepth++
+}
+
+func (c *Compiler) endScope() {
+	fc := c.current
+	fc.scopeDepth--
+
+	for len(fc.locals) > 0 && fc.locals[len(fc.locals)-1].depth > fc.scopeDepth {
+		c.emitPopLocal(fc.locals[len(fc.locals)-1])
+		fc.locals = fc.locals[:len(fc.locals)-1]
+	}
+}
+
+func (c *Compiler) emitPopLocal(local vmLocal) {
+	if local.isCaptured {
+		c.emitOp(OP_CLOSE_UPVALUE)
+	} else {
+		c.emitOp(OP_POP)
+	}
+}
+
+// pops the locals deeper than depth without forgetting them, used when
+// break and continue jump out of scopes that are still being compiled
+func (c *Compiler) emitPopLocalsDeeperThan(depth int) {
+	locals := c.current.locals
+	for i := len(locals) - 1; i >= 0 && locals[i].depth > depth; i-- {
+		c.emitPopLocal(locals[i])
+	}
+}
+
+func (c *Compiler) addLocal(name string, maybeUninitialized bool) {
+	if len(c.current.locals) == maxLocals {
+		c.error("Too many local variables in function.")
+		return
+	}
+
+	c.current.locals = append(c.current.locals, vmLocal{
+		name:               name,
+		depth:              c.current.scopeDepth,
+		maybeUninitialized: maybeUninitialized,
+	})
+}
+
+// the value of the variable has to be on top of the stack, it either
+// becomes a local slot or is moved into the globals
+func (c *Compiler) defineVariable(name Token, maybeUninitialized bool) {
+	if c.current.scopeDepth > 0 {
+		c.addLocal(name.lexeme, maybeUninitialized)
+		return
+	}
+
+	c.emitOpShort(OP_DEFINE_GLOBAL, c.makeConstant(name.lexeme))
+}
+
+func resolveLocal(fc *funcCompiler, name string) (int, bool) {
+	for i := len(fc.locals) - 1; i >= 0; i-- {
+		if fc.locals[i].name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func (c *Compiler) resolveUpvalue(fc *funcCompiler, name string) (int, bool) {
+	if fc.enclosing == nil {
+		return -1, false
+	}
+
+	if local, ok := resolveLocal(fc.enclosing, name); ok {
+		fc.enclosing.locals[local].isCaptured = true
+		maybeUninitialized := fc.enclosing.locals[local].maybeUninitialized
+		return c.addUpvalue(fc, byte(local), true, maybeUninitialized), true
+	}
+
+	if upvalue, ok := c.resolveUpvalue(fc.enclosing, name); ok {
+		maybeUninitialized := fc.enclosing.upvalues[upvalue].maybeUninitialized
+		return c.addUpvalue(fc, byte(upvalue), false, maybeUninitialized), true
+	}
+
+	return -1, false
+}
+
+func (c *Compiler) addUpvalue(fc *funcCompiler, index byte, isLocal bool, maybeUninitialized bool) int {
+	for i, upvalue := range fc.upvalues {
+		if upvalue.index == index && upvalue.isLocal == isLocal {
+			return i
+		}
+	}
+
+	if len(fc.upvalues) == maxLocals {
+		c.error("Too many closure variables in function.")
+		return 0
+	}
+
+	fc.upvalues = append(fc.upvalues, vmUpvalueRef{index, isLocal, maybeUninitialized})
+	fc.function.upvalueCount = len(fc.upvalues)
+	return len(fc.upvalues) - 1
+}
+
+func (c *Compiler) getVariable(name Token) {
+	c.token = name
+
+	if slot, ok := resolveLocal(c.current, name.lexeme); ok {
+		c.emitOpByte(OP_GET_LOCAL, byte(slot))
+		if c.current.locals[slot].maybeUninitialized {
+			c.emitOpShort(OP_CHECK_INITIALIZED, c.makeConstant(name.lexeme))
+		}
+		return
+	}
+
+	if index, ok := c.resolveUpvalue(c.current, name.lexeme); ok {
+		c.emitOpByte(OP_GET_UPVALUE, byte(index))
+		if c.current.upvalues[index].maybeUninitialized {
+			c.emitOpShort(OP_CHECK_INITIALIZED, c.makeConstant(name.lexeme))
+		}
+		return
+	}
+
+	c.emitOpShort(OP_GET_GLOBAL, c.makeConstant(name.lexeme))
+}
+
+func (c *Compiler) setVariable(name Token) {
+	c.token = name
+
+	if slot, ok := resolveLocal(c.current, name.lexeme); ok {
+		c.emitOpByte(OP_SET_LOCAL, byte(slot))
+		return
+	}
+
+	if index, ok := c.resolveUpvalue(c.current, name.lexeme); ok {
+		c.emitOpByte(OP_SET_UPVALUE, byte(index))
+		return
+	}
+
+	c.emitOpShort(OP_SET_GLOBAL, c.makeConstant(name.lexeme))
+}
+
+// compiles the body of a function and emits the closure that wraps it
+func (c *Compiler) function(stmt *Function, kind functionType) {
+	c.beginFunction(functionName(stmt), kind)
+	c.current.function.arity = len(stmt.params)
+	c.current.function.isGenerator = stmt.isGenerator
+
+	c.beginScope()
+	for _, param := range stmt.params {
+		c.token = param
+		c.addLocal(param.lexeme, false)
+	}
+
+	for _, s := range stmt.body {
+		c.compileStmt(s)
+	}
+
+	// no endScope, returning from the frame discards all of its slots
+	function, upvalues := c.endFunction()
+
+	c.token = stmt.name
+	c.emitOpShort(OP_CLOSURE, c.makeConstant(function))
+	for _, upvalue := range upvalues {
+		if upvalue.isLocal {
+			c.emitByte(1)
+		} else {
+			c.emitByte(0)
+		}
+		c.emitByte(upvalue.index)
+	}
+}
+
+// statements
+
+func (c *Compiler) VisitBlockStmt(stmt *Block) any {
+	c.block(stmt.statements)
+	return nil
+}
+
+func (c *Compiler) block(statements []Stmt) {
+	c.beginScope()
+	for _, s := range statements {
+		c.compileStmt(s)
+	}
+	c.endScope()
+}
+
+func (c *Compiler) VisitClassStmt(stmt *Class) any {
+	c.token = stmt.name
+	nameConstant := c.makeConstant(stmt.name.lexeme)
+
+	c.emitOpShort(OP_CLASS, nameConstant)
+	c.defineVariable(stmt.name, false)
+
+	// the class is loaded back on the stack while its methods are attached
+	c.getVariable(stmt.name)
+	for _, method := range stmt.methods {
+		kind := functionMethod
+		if method.name.lexeme == "init" {
+			kind = functionInitializer
+		}
+
+		c.function(method, kind)
+		c.emitOpShort(OP_METHOD, c.makeConstant(method.name.lexeme))
+	}
+	c.emitOp(OP_POP)
+
+	return nil
+}
+
+func (c *Compiler) VisitExpressionStmt(stmt *Expression) any {
+	c.compileExpr(stmt.expression)
+	c.emitOp(OP_POP)
+	return nil
+}
+
+func (c *Compiler) VisitFunctionStmt(stmt *Function) any {
+	c.token = stmt.name
+
+	// a local function is in scope inside of its own body so that it can recurse
+	if c.current.scopeDepth > 0 {
+		c.addLocal(stmt.name.lexeme, false)
+		c.function(stmt, functionFunction)
+		return nil
+	}
+
+	c.function(stmt, functionFunction)
+	c.defineVariable(stmt.name, false)
+	return nil
+}
+
+func (c *Compiler) VisitPrintStmt(stmt *Print) any {
+	c.compileExpr(stmt.expression)
+	c.emitOp(OP_PRINT)
+	return nil
+}
+
+func (c *Compiler) VisitReturnStmt(stmt *Return) any {
+	c.token = stmt.keyword
+
+	if len(c.current.tries) == 0 {
+		if stmt.value == nil {
+			c.emitReturn()
+		} else {
+			c.compileExpr(stmt.value)
+			c.emitOp(OP_RETURN)
+		}
+		return nil
+	}
+
+	switch {
+	case stmt.value != nil:
+		c.compileExpr(stmt.value)
+	case c.current.kind == functionInitializer:
+		c.emitOpByte(OP_GET_LOCAL, 0)
+	default:
+		c.emitOp(OP_NIL)
+	}
+
+	// the returned value waits in a slot of its own while the finally blocks run
+	c.addLocal("", false)
+	c.exitTries(0)
+	c.current.locals = c.current.locals[:len(c.current.locals)-1]
+
+	c.emitOp(OP_RETURN)
+	return nil
+}
+
+func (c *Compiler) VisitYieldStmt(stmt *Yield) any {
+	c.token = stmt.keyword
+
+	if stmt.value == nil {
+		c.emitOp(OP_NIL)
+	} else {
+		c.compileExpr(stmt.value)
+	}
+
+	c.emitOp(OP_YIELD)
+	return nil
+}
+
+func (c *Compiler) VisitImportStmt(stmt *Import) any {
+	c.token = stmt.path
+	c.emitOpShort(OP_IMPORT, c.makeConstant(stmt.path.object.(string)))
+	c.defineVariable(stmt.name, false)
+	return nil
+}
+
+func (c *Compiler) VisitThrowStmt(stmt *Throw) any {
+	c.compileExpr(stmt.value)
+
+	c.token = stmt.keyword
+	c.emitOp(OP_THROW)
+	return nil
+}
+
+// the handler of the body starts with the error on top of the stack, the catch
+// clause binds it as a local, without a catch clause or when the catch clause
+// fails too the finally block runs and raises the error again
+func (c *Compiler) VisitTryStmt(stmt *Try) any {
+	c.token = stmt.keyword
+	fc := c.current
+
+	try := &vmTry{loopDepth: len(fc.loops), finallyBody: stmt.finallyBody}
+	fc.tries = append(fc.tries, try)
+
+	handler := c.emitJump(OP_TRY)
+	c.block(stmt.body)
+	c.emitOp(OP_END_TRY)
+
+	fc.tries = fc.tries[:len(fc.tries)-1]
+	c.block(stmt.finallyBody)
+	exitJumps := []int{c.emitJump(OP_JUMP)}
+
+	c.patchJump(handler)
+
+	if stmt.hasCatch {
+		c.beginScope()
+		c.emitOp(OP_CATCH)
+		c.addLocal(stmt.catchName.lexeme, false)
+
+		if stmt.finallyBody != nil {
+			handler = c.emitJump(OP_TRY)
+			fc.tries = append(fc.tries, try)
+		}
+
+		for _, s := range stmt.catchBody {
+			c.compileStmt(s)
+		}
+
+		if stmt.finallyBody != nil {
+			c.emitOp(OP_END_TRY)
+			fc.tries = fc.tries[:len(fc.tries)-1]
+		}
+		c.endScope()
+
+		c.block(stmt.finallyBody)
+		exitJumps = append(exitJumps, c.emitJump(OP_JUMP))
+
+		if stmt.finallyBody != nil {
+			c.patchJump(handler)
+		}
+	}
+
+	if !stmt.hasCatch || stmt.finallyBody != nil {
+		// the error is kept in a slot of its own, OP_THROW consumes it
+		c.beginScope()
+		c.addLocal("", false)
+		c.block(stmt.finallyBody)
+		c.emitOp(OP_THROW)
+		fc.locals = fc.locals[:len(fc.locals)-1]
+		fc.scopeDepth--
+	}
+
+	for _, jump := range exitJumps {
+		c.patchJump(jump)
+	}
+	return nil
+}
+
+// drops the handlers and runs the finally blocks of the try statements that
+// return, break or continue is leaving, those inside of loopDepth loops
+func (c *Compiler) exitTries(loopDepth int) {
+	fc := c.current
+	tries := fc.tries
+	defer func() {
+		fc.tries = tries
+	}()
+
+	for i := len(tries) - 1; i >= 0 && tries[i].loopDepth >= loopDepth; i-- {
+		c.emitOp(OP_END_TRY)
+
+		// a finally block that itself leaves only runs the outer ones
+		fc.tries = tries[:i]
+		c.block(tries[i].finallyBody)
+	}
+}
+
+func (c *Compiler) VisitVarStmt(stmt *Var) any {
+	c.token = stmt.name
+
+	if stmt.initializer == nil {
+		c.emitOp(OP_UNINITIALIZED)
+	} else {
+		c.compileExpr(stmt.initializer)
+	}
+
+	c.defineVariable(stmt.name, stmt.initializer == nil)
+	return nil
+}
+
+func (c *Compiler) VisitIfStmt(stmt *If) any {
+	c.compileExpr(stmt.condition)
+
+	thenJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileStmt(stmt.thenBranch)
+
+	elseJump := c.emitJump(OP_JUMP)
+	c.patchJump(thenJump)
+	c.emitOp(OP_POP)
+
+	if stmt.elseBranch != nil {
+		c.compileStmt(stmt.elseBranch)
+	}
+	c.patchJump(elseJump)
+
+	return nil
+}
+
+func (c *Compiler) VisitWhileStmt(stmt *While) any {
+	loop := &vmLoop{scopeDepth: c.current.scopeDepth}
+	c.current.loops = append(c.current.loops, loop)
+
+	loopStart := len(c.chunk().code)
+	c.compileExpr(stmt.condition)
+
+	exitJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileStmt(stmt.body)
+
+	// continue lands here so that the increment of a for loop still runs
+	for _, jump := range loop.continueJumps {
+		c.patchJump(jump)
+	}
+	if stmt.increment != nil {
+		c.compileExpr(stmt.increment)
+		c.emitOp(OP_POP)
+	}
+	c.emitLoop(loopStart)
+
+	c.patchJump(exitJump)
+	c.emitOp(OP_POP)
+
+	for _, jump := range loop.breakJumps {
+		c.patchJump(jump)
+	}
+
+	c.current.loops = c.current.loops[:len(c.current.loops)-1]
+	return nil
+}
+
+func (c *Compiler) VisitBreakStmt(stmt *Break) any {
+	c.token = stmt.keyword
+	loop := c.current.loops[len(c.current.loops)-1]
+
+	c.exitTries(len(c.current.loops))
+	c.emitPopLocalsDeeperThan(loop.scopeDepth)
+	loop.breakJumps = append(loop.breakJumps, c.emitJump(OP_JUMP))
+	return nil
+}
+
+func (c *Compiler) VisitContinueStmt(stmt *Continue) any {
+	c.token = stmt.keyword
+	loop := c.current.loops[len(c.current.loops)-1]
+
+	c.exitTries(len(c.current.loops))
+	c.emitPopLocalsDeeperThan(loop.scopeDepth)
+	loop.continueJumps = append(loop.continueJumps, c.emitJump(OP_JUMP))
+	return nil
+}
+
+// expressions
+
+func (c *Compiler) VisitAssignExpr(expr *Assign) any {
+	c.compileExpr(expr.value)
+	c.setVariable(expr.name)
+	return nil
+}
+
+func (c *Compiler) VisitBinaryExpr(expr *Binary) any {
+	c.compileExpr(expr.left)
+	c.compileExpr(expr.right)
+
+	c.token = expr.operator
+	switch expr.operator.tokenType {
+	case PLUS:
+		c.emitOp(OP_ADD)
+	case MINUS:
+		c.emitOp(OP_SUBTRACT)
+	case STAR:
+		c.emitOp(OP_MULTIPLY)
+	case SLASH:
+		c.emitOp(OP_DIVIDE)
+	case PERCENT:
+		c.emitOp(OP_MODULO)
+	case GREATER:
+		c.emitOp(OP_GREATER)
+	case GREATER_EQUAL:
+		c.emitOp(OP_GREATER_EQUAL)
+	case LESS:
+		c.emitOp(OP_LESS)
+	case LESS_EQUAL:
+		c.emitOp(OP_LESS_EQUAL)
+	case EQUAL_EQUAL:
+		c.emitOp(OP_EQUAL)
+	case BANG_EQUAL:
+		c.emitOp(OP_NOT_EQUAL)
+	default:
+		c.error("Unknown operator, should have failed in parsing.")
+	}
+	return nil
+}
+
+func (c *Compiler) VisitCallExpr(expr *Call) any {
+	c.compileExpr(expr.callee)
+	for _, argument := range expr.arguments {
+		c.compileExpr(argument)
+	}
+
+	c.token = expr.paren
+	c.emitOpByte(OP_CALL, byte(len(expr.arguments)))
+	return nil
+}
+
+func (c *Compiler) VisitGetExpr(expr *Get) any {
+	c.compileExpr(expr.object)
+
+	c.token = expr.name
+	c.emitOpShort(OP_GET_PROPERTY, c.makeConstant(expr.name.lexeme))
+	return nil
+}
+
+func (c *Compiler) VisitSetExpr(expr *Set) any {
+	c.compileExpr(expr.object)
+	c.compileExpr(expr.value)
+
+	c.token = expr.name
+	c.emitOpShort(OP_SET_PROPERTY, c.makeConstant(expr.name.lexeme))
+	return nil
+}
+
+func (c *Compiler) VisitInterpolationExpr(expr *Interpolation) any {
+	for _, part := range expr.parts {
+		c.compileExpr(part)
+	}
+
+	if len(expr.parts) > math.MaxUint16 {
+		c.error("Too many interpolations in a string.")
+		return nil
+	}
+	c.emitOpShort(OP_INTERPOLATE, len(expr.parts))
+	return nil
+}
+
+func (c *Compiler) VisitLambdaExpr(expr *Lambda) any {
+	c.function(expr.function, functionFunction)
+	return nil
+}
+
+func (c *Compiler) VisitListExpr(expr *List) any {
+	for _, element := range expr.elements {
+		c.compileExpr(element)
+	}
+
+	c.token = expr.bracket
+	if len(expr.elements) > math.MaxUint16 {
+		c.error("Too many elements in a list literal.")
+		return nil
+	}
+	c.emitOpShort(OP_LIST, len(expr.elements))
+	return nil
+}
+
+func (c *Compiler) VisitMapExpr(expr *Map) any {
+	for i := range expr.keys {
+		c.compileExpr(expr.keys[i])
+		c.compileExpr(expr.values[i])
+	}
+
+	c.token = expr.brace
+	if len(expr.keys) > math.MaxUint16 {
+		c.error("Too many entries in a map literal.")
+		return nil
+	}
+	c.emitOpShort(OP_MAP, len(expr.keys))
+	return nil
+}
+
+func (c *Compiler) VisitIndexExpr(expr *Index) any {
+	c.compileExpr(expr.object)
+	c.compileExpr(expr.index)
+
+	c.token = expr.bracket
+	c.emitOp(OP_GET_INDEX)
+	return nil
+}
+
+func (c *Compiler) VisitSetIndexExpr(expr *SetIndex) any {
+	c.compileExpr(expr.object)
+	c.compileExpr(expr.index)
+	c.compileExpr(expr.value)
+
+	c.token = expr.bracket
+	c.emitOp(OP_SET_INDEX)
+	return nil
+}
+
+func (c *Compiler) VisitThisExpr(expr *This) any {
+	c.getVariable(expr.keyword)
+	return nil
+}
+
+func (c *Compiler) VisitGroupingExpr(expr *Grouping) any {
+	c.compileExpr(expr.expression)
+	return nil
+}
+
+func (c *Compiler) VisitLiteralExpr(expr *Literal) any {
+	switch expr.value {
+	case nil:
+		c.emitOp(OP_NIL)
+	case true:
+		c.emitOp(OP_TRUE)
+	case false:
+		c.emitOp(OP_FALSE)
+	default:
+		c.emitConstant(expr.value)
+	}
+	return nil
+}
+
+func (c *Compiler) VisitLogicalExpr(expr *Logical) any {
+	c.compileExpr(expr.left)
+
+	c.token = expr.operator
+	if expr.operator.tokenType == OR {
+		elseJump := c.emitJump(OP_JUMP_IF_FALSE)
+		endJump := c.emitJump(OP_JUMP)
+
+		c.patchJump(elseJump)
+		c.emitOp(OP_POP)
+		c.compileExpr(expr.right)
+
+		c.patchJump(endJump)
+		return nil
+	}
+
+	endJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileExpr(expr.right)
+	c.patchJump(endJump)
+	return nil
+}
+
+func (c *Compiler) VisitUnaryExpr(expr *Unary) any {
+	c.compileExpr(expr.right)
+
+	c.token = expr.operator
+	switch expr.operator.tokenType {
+	case MINUS:
+		c.emitOp(OP_NEGATE)
+	case BANG:
+		c.emitOp(OP_NOT)
+	}
+	return nil
+}
+
+func (c *Compiler) VisitTernaryExpr(expr *Ternary) any {
+	c.compileExpr(expr.condition)
+
+	elseJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileExpr(expr.outcome1)
+
+	endJump := c.emitJump(OP_JUMP)
+	c.patchJump(elseJump)
+	c.emitOp(OP_POP)
+	c.compileExpr(expr.outcome2)
+
+	c.patchJump(endJump)
+	return nil
+}
+
+func (c *Compiler) VisitCommaExpr(expr *Comma) any {
+	for index, e := range expr.exprs {
+		c.compileExpr(e)
+		if index < len(expr.exprs)-1 {
+			c.emitOp(OP_POP)
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) VisitVariableExpr(expr *Variable) any {
+	c.getVariable(expr.name)
+	return nil
+}
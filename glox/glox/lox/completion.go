@@ -0,0 +1,23 @@
+package lox
+
+type completionKind int
+
+const (
+	completionReturn completionKind = iota
+	completionBreak
+	completionContinue
+)
+
+// Completion tells how a statement that did not simply run to its end finished,
+// it is passed up through execute and executeBlock until a function call or a
+// loop consumes it, statements that finish normally return nil
+type Completion struct {
+	kind  completionKind
+	value any // the returned value of completionReturn
+}
+
+// break and continue carry no value so the same completions are shared
+var (
+	breakCompletion    = &Completion{kind: completionBreak}
+	continueCompletion = &Completion{kind: completionContinue}
+)
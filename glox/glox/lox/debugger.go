@@ -0,0 +1,444 @@
+package lox
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// StopReason tells why a debugged interpreter paused
+type StopReason string
+
+const (
+	StopEntry      StopReason = "entry"
+	StopBreakpoint StopReason = "breakpoint"
+	StopStep       StopReason = "step"
+	StopPause      StopReason = "pause"
+)
+
+type stepMode int
+
+const (
+	stepContinue stepMode = iota
+	stepIn
+	stepOver
+	stepOut
+)
+
+// ErrStopped is the error of a run whose debugger was stopped
+var ErrStopped = errors.New("Stopped by the debugger.")
+
+// Debugger pauses the tree-walking interpreter before statements, the ones
+// with a breakpoint on their line and the ones a step ends at
+//
+// while paused the interpreter calls the function given to NewDebugger on its
+// own goroutine, the function can look at the frames, their scopes and
+// evaluate expressions in them, once it returns the interpreter goes on as
+// told by the last call to Continue, StepIn, StepOver or StepOut
+type Debugger struct {
+	paused func(reason StopReason)
+
+	// lines with a breakpoint by absolute path of the file, set from any goroutine
+	mu          sync.Mutex
+	breakpoints map[string]map[int]bool
+
+	// absolute paths of the files of the tokens
+	paths map[string]string
+
+	// set from any goroutine to pause or to stop before the next statement
+	pauseRequested atomic.Bool
+	stopRequested  atomic.Bool
+
+	// what to do after the pause
+	mode stepMode
+
+	// the statement run last and the one the interpreter last paused at
+	previous debugPosition
+	stopped  debugPosition
+
+	// while paused, the interpreter that paused and its frames
+	current *Interpreter
+	frames  []DebugFrame
+
+	// an expression is being evaluated, its statements do not pause
+	evaluating bool
+}
+
+// debugPosition is a statement being run and how many calls deep it is
+type debugPosition struct {
+	stmt  Stmt
+	file  string
+	line  int
+	depth int
+}
+
+// whether the statement at p counts as a new place to pause at coming from
+// q, which it does unless it is another statement on the same line, a loop
+// coming back to the same statement does count
+func (p debugPosition) moved(q debugPosition) bool {
+	return p.stmt == q.stmt || p.file != q.file || p.line != q.line || p.depth != q.depth
+}
+
+// DebugFrame is a call the paused interpreter is running
+type DebugFrame struct {
+	Function string
+	File     string
+	Line     int
+	Column   int
+
+	// the environment the frame is running in
+	env *Environment
+}
+
+// DebugScope is one environment of the chain a frame runs in
+type DebugScope struct {
+	// "locals" for the innermost, "globals" for the top-level scope of the
+	// script or module and "enclosing" for any in between
+	Name      string
+	Variables []DebugVariable
+}
+
+type DebugVariable struct {
+	Name string
+
+	// the value as print shows it
+	Value string
+}
+
+// NewDebugger attaches a debugger to the interpreter, it pauses before the
+// first statement the interpreter runs
+func NewDebugger(interpreter *Interpreter, paused func(reason StopReason)) *Debugger {
+	d := &Debugger{
+		paused:      paused,
+		breakpoints: make(map[string]map[int]bool, 4),
+		paths:       make(map[string]string, 4),
+		mode:        stepIn,
+	}
+	interpreter.debugger = d
+	return d
+}
+
+func absolutePath(file string) string {
+	if file == "" {
+		return ""
+	}
+	if absolute, err := filepath.Abs(file); err == nil {
+		return absolute
+	}
+	return file
+}
+
+// SetBreakpoints replaces the breakpoints of the file with the given lines,
+// the file is the path of a script or module, the source given to Run is ""
+func (d *Debugger) SetBreakpoints(file string, lines []int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	breakpoints := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		breakpoints[line] = true
+	}
+	d.breakpoints[absolutePath(file)] = breakpoints
+}
+
+// Breakpoints returns the lines of the file that have a breakpoint, in order
+func (d *Debugger) Breakpoints(file string) []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var lines []int
+	for line := range d.breakpoints[absolutePath(file)] {
+		lines = append(lines, line)
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+func (d *Debugger) hasBreakpoint(file string, line int) bool {
+	path, ok := d.paths[file]
+	if !ok {
+		path = absolutePath(file)
+		d.paths[file] = path
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.breakpoints[path][line]
+}
+
+// Pause makes the interpreter pause before its next statement, it can be
+// called from any goroutine
+func (d *Debugger) Pause() {
+	d.pauseRequested.Store(true)
+}
+
+// Stop ends the run before its next statement, the run returns ErrStopped,
+// it can be called from any goroutine
+func (d *Debugger) Stop() {
+	d.stopRequested.Store(true)
+}
+
+// Continue runs on up to the next breakpoint
+func (d *Debugger) Continue() {
+	d.mode = stepContinue
+}
+
+// StepIn pauses at the next statement, inside the function called by the
+// current one if it calls any
+func (d *Debugger) StepIn() {
+	d.mode = stepIn
+}
+
+// StepOver pauses at the next statement of the current function, or of its
+// caller once it returns
+func (d *Debugger) StepOver() {
+	d.mode = stepOver
+}
+
+// StepOut pauses at the next statement after the current function returned
+func (d *Debugger) StepOut() {
+	d.mode = stepOut
+}
+
+// called by the interpreter before every statement
+func (d *Debugger) before(i *Interpreter, stmt Stmt) {
+	if d.evaluating {
+		return
+	}
+	if d.stopRequested.Load() {
+		panic(ErrStopped)
+	}
+	at, ok := statementToken(stmt)
+	if !ok {
+		return
+	}
+
+	position := debugPosition{stmt, at.file, at.line, i.depth()}
+	previous := d.previous
+	d.previous = position
+
+	var reason StopReason
+	switch {
+	case d.pauseRequested.Swap(false):
+		reason = StopPause
+	case position.moved(previous) && d.hasBreakpoint(at.file, at.line):
+		reason = StopBreakpoint
+	case d.mode == stepIn && d.stopped.stmt == nil:
+		reason = StopEntry
+	case d.mode == stepIn && position.moved(d.stopped),
+		d.mode == stepOver && position.depth <= d.stopped.depth && position.moved(d.stopped),
+		d.mode == stepOut && position.depth < d.stopped.depth:
+		reason = StopStep
+	default:
+		return
+	}
+
+	d.stopped = position
+	d.current = i
+	d.frames = i.debugFrames(at)
+	d.paused(reason)
+	d.current = nil
+	d.frames = nil
+}
+
+// Frames returns the calls the paused interpreter is running, innermost first
+func (d *Debugger) Frames() []DebugFrame {
+	return d.frames
+}
+
+// Scopes returns the environments the frame runs in, innermost first, with
+// their variables in order of name
+func (d *Debugger) Scopes(frame int) []DebugScope {
+	if frame < 0 || frame >= len(d.frames) {
+		return nil
+	}
+
+	var scopes []DebugScope
+	for env := d.frames[frame].env; env.enclosing != nil; env = env.enclosing {
+		scope := DebugScope{Name: "enclosing"}
+		if len(scopes) == 0 {
+			scope.Name = "locals"
+		}
+		if env.enclosing.enclosing == nil {
+			scope.Name = "globals"
+		}
+
+		for name, value := range env.values {
+			shown := stringify(value)
+			if !env.initialized[name] {
+				shown = "<uninitialized>"
+			}
+			scope.Variables = append(scope.Variables, DebugVariable{name, shown})
+		}
+		slices.SortFunc(scope.Variables, func(a, b DebugVariable) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
+
+// Evaluate evaluates the expression in the frame as if it was written where
+// the frame is paused, assignments and calls in it take effect
+func (d *Debugger) Evaluate(frame int, source string) (value Value, err error) {
+	if d.current == nil {
+		return nil, errors.New("The program is not paused.")
+	}
+	if frame < 0 || frame >= len(d.frames) {
+		return nil, errors.New("No such frame.")
+	}
+	env := d.frames[frame].env
+
+	expr, err := parseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+
+	// the local scopes of the resolver are the environments of the frame
+	i := d.current
+	resolver := NewResolver()
+	for scope := env; scope.enclosing != nil && scope.enclosing.enclosing != nil; scope = scope.enclosing {
+		names := make(map[string]bool, len(scope.values))
+		for name := range scope.values {
+			names[name] = true
+		}
+		resolver.scopes = slices.Insert(resolver.scopes, 0, names)
+		if names["this"] {
+			resolver.currentClass = classClass
+		}
+	}
+	resolver.resolveExpr(expr)
+	if len(resolver.errors) > 0 {
+		return nil, resolver.errors
+	}
+
+	enclosing, depth := i.environment, len(i.callStack)
+	i.environment = env
+	d.evaluating = true
+	defer func() {
+		i.environment = enclosing
+		d.evaluating = false
+		if r := recover(); r != nil {
+			runtimeErr, ok := r.(*RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			i.callStack = i.callStack[:depth]
+			value, err = nil, runtimeErr
+		}
+	}()
+
+	return i.evaluate(expr), nil
+}
+
+// parses a single expression, as typed into a debugger
+func parseExpression(source string) (expr Expr, err error) {
+	scanner := NewScanner(source)
+	parser := NewParser(scanner.scanTokens())
+	if len(scanner.errors) > 0 {
+		return nil, scanner.errors
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*ParseError); !ok {
+				panic(r)
+			}
+			expr, err = nil, parser.errors
+		}
+	}()
+
+	expr = parser.expression()
+	parser.match(SEMICOLON)
+	if !parser.isAtEnd() {
+		parser.error(parser.peek(), "Expect end of expression.")
+	}
+	return expr, nil
+}
+
+// the token a statement pauses at, the first one of the statement when
+// there is one, blocks never pause but their statements do
+func statementToken(stmt Stmt) (Token, bool) {
+	switch stmt := stmt.(type) {
+	case *Var:
+		return stmt.name, true
+	case *Function:
+		return stmt.name, true
+	case *Class:
+		return stmt.name, true
+	case *Print:
+		return stmt.keyword, true
+	case *If:
+		return stmt.keyword, true
+	case *While:
+		return stmt.keyword, true
+	case *Return:
+		return stmt.keyword, true
+	case *Yield:
+		return stmt.keyword, true
+	case *Throw:
+		return stmt.keyword, true
+	case *Try:
+		return stmt.keyword, true
+	case *Import:
+		return stmt.keyword, true
+	case *Break:
+		return stmt.keyword, true
+	case *Continue:
+		return stmt.keyword, true
+	case *Expression:
+		return expressionToken(stmt.expression)
+	}
+	return Token{}, false
+}
+
+// the leftmost token of the expression, literals have none
+func expressionToken(expr Expr) (Token, bool) {
+	switch expr := expr.(type) {
+	case *Assign:
+		return expr.name, true
+	case *Binary:
+		return expressionToken(expr.left)
+	case *Call:
+		return expressionToken(expr.callee)
+	case *Get:
+		return expressionToken(expr.object)
+	case *Grouping:
+		return expressionToken(expr.expression)
+	case *Index:
+		return expressionToken(expr.object)
+	case *Interpolation:
+		for _, part := range expr.parts {
+			if token, ok := expressionToken(part); ok {
+				return token, true
+			}
+		}
+	case *Lambda:
+		return expr.function.name, true
+	case *List:
+		return expr.bracket, true
+	case *Logical:
+		return expressionToken(expr.left)
+	case *Map:
+		return expr.brace, true
+	case *Set:
+		return expressionToken(expr.object)
+	case *SetIndex:
+		return expressionToken(expr.object)
+	case *This:
+		return expr.keyword, true
+	case *Unary:
+		return expr.operator, true
+	case *Ternary:
+		return expressionToken(expr.condition)
+	case *Comma:
+		return expressionToken(expr.exprs[0])
+	case *Variable:
+		return expr.name, true
+	}
+	return Token{}, false
+}
@@ -0,0 +1,136 @@
+package lox
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const debuggedSource = `fun add(a, b) {
+    var sum = a + b;
+    return sum;
+}
+var x = 1;
+var y = add(x, 2);
+print y;
+`
+
+// runs the source under a debugger, at every pause the step function gets
+// the reason and tells it how to go on, the lines paused at are returned
+func debug(t *testing.T, breakpoints []int, step func(d *Debugger, reason StopReason)) []string {
+	t.Helper()
+
+	i := NewInterpreter()
+	i.SetOutput(&strings.Builder{})
+
+	var d *Debugger
+	var stops []string
+	d = NewDebugger(i, func(reason StopReason) {
+		frame := d.Frames()[0]
+		stops = append(stops, fmt.Sprintf("%s %s:%d", reason, frame.Function, frame.Line))
+		step(d, reason)
+	})
+	d.SetBreakpoints("", breakpoints)
+
+	if err := i.Run(debuggedSource); err != nil {
+		t.Fatal(err)
+	}
+	return stops
+}
+
+func TestDebuggerBreakpoints(t *testing.T) {
+	stops := debug(t, []int{2, 7}, func(d *Debugger, reason StopReason) {
+		d.Continue()
+	})
+
+	expected := []string{"entry script:1", "breakpoint add:2", "breakpoint script:7"}
+	if !slices.Equal(stops, expected) {
+		t.Errorf("expected stops %v, got %v", expected, stops)
+	}
+}
+
+func TestDebuggerSteps(t *testing.T) {
+	steps := []func(d *Debugger){
+		(*Debugger).StepOver, (*Debugger).StepOver, (*Debugger).StepIn,
+		(*Debugger).StepOver, (*Debugger).StepOut, (*Debugger).StepOver,
+	}
+	stops := debug(t, nil, func(d *Debugger, reason StopReason) {
+		steps[0](d)
+		steps = steps[1:]
+	})
+
+	expected := []string{
+		"entry script:1", "step script:5", "step script:6",
+		"step add:2", "step add:3", "step script:7",
+	}
+	if !slices.Equal(stops, expected) {
+		t.Errorf("expected stops %v, got %v", expected, stops)
+	}
+}
+
+func TestDebuggerInspect(t *testing.T) {
+	inspected := false
+	debug(t, []int{3}, func(d *Debugger, reason StopReason) {
+		d.Continue()
+		if reason != StopBreakpoint {
+			return
+		}
+		inspected = true
+
+		frames := d.Frames()
+		if len(frames) != 2 || frames[1].Function != "script" || frames[1].Line != 6 {
+			t.Errorf("expected add called from line 6, got %+v", frames)
+		}
+
+		scopes := d.Scopes(0)
+		names := make([]string, len(scopes))
+		for i, scope := range scopes {
+			names[i] = scope.Name
+		}
+		if !slices.Equal(names, []string{"locals", "globals"}) {
+			t.Fatalf("expected the scopes of a function body, got %v", names)
+		}
+		if locals := fmt.Sprint(scopes[0].Variables); locals != "[{a 1} {b 2} {sum 3}]" {
+			t.Errorf("expected the parameters and sum in the locals, got %s", locals)
+		}
+
+		if value, err := d.Evaluate(0, "sum * 10 + x"); err != nil || value != 31.0 {
+			t.Errorf("expected 31, got %v, %v", value, err)
+		}
+		if value, err := d.Evaluate(1, "x = x + 1"); err != nil || value != 2.0 {
+			t.Errorf("expected x assigned 2 in the script frame, got %v, %v", value, err)
+		}
+		if _, err := d.Evaluate(1, "sum"); err == nil {
+			t.Error("expected sum to be undefined in the script frame")
+		}
+		if _, err := d.Evaluate(0, "sum + nil"); err == nil {
+			t.Error("expected a runtime error")
+		}
+		if _, err := d.Evaluate(0, "sum +"); err == nil {
+			t.Error("expected a parse error")
+		}
+	})
+
+	if !inspected {
+		t.Error("never stopped at the breakpoint")
+	}
+}
+
+func TestDebuggerStop(t *testing.T) {
+	i := NewInterpreter()
+	var out strings.Builder
+	i.SetOutput(&out)
+
+	var d *Debugger
+	d = NewDebugger(i, func(reason StopReason) {
+		d.Stop()
+	})
+
+	if err := i.Run(debuggedSource); err != ErrStopped {
+		t.Errorf("expected the run to be stopped, got %v", err)
+	}
+	if out.String() != "" {
+		t.Errorf("expected nothing printed, got %q", out.String())
+	}
+}
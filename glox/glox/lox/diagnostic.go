@@ -0,0 +1,147 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RunScript runs the script in the file the way the glox command does, the
+// error that stopped it is written to stderr as FormatError shows it and the
+// exit status ExitCode gives for it is returned, the engine is closed then
+func RunScript(engine Engine, path string, stderr io.Writer) int {
+	defer engine.Close()
+
+	err := engine.RunFile(path)
+	if err != nil {
+		source, _ := os.ReadFile(path)
+		fmt.Fprintln(stderr, FormatError(err, path, string(source)))
+	}
+	return ExitCode(err)
+}
+
+// FormatError renders an error returned by Run or Eval the way compilers do,
+// with the file name, the position and the source line the error points at:
+//
+//	script.lox:1:5: Error at '=': Expect variable name.
+//	  1 | var = 3;
+//	    |     ^
+//
+// errors in imported modules are shown with the path and source of their file
+func FormatError(err error, filename string, source string) string {
+	var compileErrors CompileErrors
+	var compileErr *CompileError
+	var runtimeErr *RuntimeError
+
+	switch {
+	case errors.As(err, &compileErrors):
+		diagnostics := make([]string, len(compileErrors))
+		for i, e := range compileErrors {
+			diagnostics[i] = formatCompileError(e, filename, source)
+		}
+		return strings.Join(diagnostics, "\n")
+	case errors.As(err, &compileErr):
+		return formatCompileError(compileErr, filename, source)
+	case errors.As(err, &runtimeErr):
+		header := "Runtime error: " + runtimeErr.Message
+		name, text := errorSource(runtimeErr.Token.file, filename, source)
+		diagnostic := formatDiagnostic(name, text, runtimeErr.Token.line, runtimeErr.Token.column, utf8.RuneCountInString(runtimeErr.Token.lexeme), header)
+		// errors outside of any function are fully described by the snippet
+		if len(runtimeErr.Trace) <= 1 {
+			return diagnostic
+		}
+
+		lines := formatTrace(runtimeErr.Trace, func(frame StackFrame) string {
+			name := filename
+			if frame.File != "" {
+				name = frame.File
+			}
+			return fmt.Sprintf("  %s:%d:%d in %s", name, frame.Line, frame.Column, frame.Function)
+		})
+		return diagnostic + "\nTraceback (innermost first):\n" + strings.Join(lines, "\n")
+	default:
+		return err.Error()
+	}
+}
+
+func formatCompileError(err *CompileError, filename string, source string) string {
+	header := "Error" + err.Where + ": " + err.Message
+	name, text := errorSource(err.File, filename, source)
+	return formatDiagnostic(name, text, err.Line, err.Column, err.Length, header)
+}
+
+// the name and the source of the file an error is in, the source is read
+// again for an error in a module other than the one that was run
+func errorSource(file string, filename string, source string) (string, string) {
+	if file == "" || file == filename {
+		return filename, source
+	}
+
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return file, ""
+	}
+	return file, string(text)
+}
+
+// the header prefixed with the position, followed by the source line with
+// a caret under the columns the error points at
+func formatDiagnostic(filename string, source string, line int, column int, length int, header string) string {
+	if column == 0 {
+		return fmt.Sprintf("%s:%d: %s", filename, line, header)
+	}
+
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return fmt.Sprintf("%s:%d:%d: %s", filename, line, column, header)
+	}
+	text := strings.TrimSuffix(lines[line-1], "\r")
+	characters := []rune(text)
+
+	// keep the tabs so the caret lines up with the source line
+	var indent strings.Builder
+	for i := 0; i < column-1 && i < len(characters); i++ {
+		if characters[i] == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+
+	// lexemes spanning several lines are only underlined on the first one
+	length = min(length, len(characters)-(column-1))
+	length = max(length, 1)
+
+	number := strconv.Itoa(line)
+	gutter := strings.Repeat(" ", len(number))
+
+	return fmt.Sprintf("%s:%d:%d: %s\n %s | %s\n %s | %s%s",
+		filename, line, column, header,
+		number, text,
+		gutter, indent.String(), strings.Repeat("^", length))
+}
+
+// formats every frame of the trace, runs of the same frame as left by deep
+// recursion are shortened to a single line
+func formatTrace(trace []StackFrame, format func(StackFrame) string) []string {
+	lines := make([]string, 0, len(trace))
+
+	for i := 0; i < len(trace); {
+		repeated := 1
+		for i+repeated < len(trace) && trace[i+repeated] == trace[i] {
+			repeated++
+		}
+
+		lines = append(lines, format(trace[i]))
+		if repeated > 1 {
+			lines = append(lines, fmt.Sprintf("  [Previous line repeated %d more times]", repeated-1))
+		}
+		i += repeated
+	}
+
+	return lines
+}
@@ -0,0 +1,43 @@
+package lox
+
+import (
+	"testing"
+)
+
+func TestFormatError(t *testing.T) {
+	source := "var a = 1;\n\tprint a +\n\t\t\"x\" * nil;\nvar = 3;"
+
+	err := NewInterpreter().Run(source)
+	expected := "test.lox:4:5: Error at '=': Expect variable name.\n" +
+		" 4 | var = 3;\n" +
+		"   |     ^"
+	if actual := FormatError(err, "test.lox", source); actual != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, actual)
+	}
+
+	source = source[:len(source)-len("var = 3;")]
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		err := engine.Run(source)
+		expected := "test.lox:3:7: Runtime error: Operands must be numbers.\n" +
+			" 3 | \t\t\"x\" * nil;\n" +
+			"   | \t\t    ^"
+		if actual := FormatError(err, "test.lox", source); actual != expected {
+			t.Errorf("%T: expected:\n%s\nactual:\n%s", engine, expected, actual)
+		}
+	}
+}
+
+func TestFormatErrorUnderlinesLexeme(t *testing.T) {
+	source := "var list = [1];\nthrow \"boom\";"
+	expected := "test.lox:2:1: Runtime error: boom\n" +
+		" 2 | throw \"boom\";\n" +
+		"   | ^^^^^"
+
+	// the VM underlines the same token as the interpreter
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		err := engine.Run(source)
+		if actual := FormatError(err, "test.lox", source); actual != expected {
+			t.Errorf("%T: expected:\n%s\nactual:\n%s", engine, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package lox
+
+type Environment struct {
+	enclosing *Environment
+
+	values      map[string]Object
+	initialized map[string]bool
+}
+
+func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{
+		enclosing:   enclosing,
+		values:      make(map[string]Object, 10),
+		initialized: make(map[string]bool, 10),
+	}
+}
+
+func (e *Environment) initialize(name string) {
+	e.initialized[name] = true
+}
+
+func (e *Environment) define(name string, value Object) {
+	e.values[name] = value
+}
+
+func (e *Environment) get(name Token) Object {
+	value, ok := e.values[name.lexeme]
+	if !ok {
+		if e.enclosing != nil {
+			return e.enclosing.get(name)
+		}
+
+		var err error = &RuntimeError{
+			Message: "Undefined variable '" + name.lexeme + "'.",
+			Token:   name,
+		}
+		panic(err)
+	}
+
+	if !e.initialized[name.lexeme] {
+		var err error = &RuntimeError{
+			Message: "Uninitialized variable '" + name.lexeme + "'.",
+			Token:   name,
+		}
+		panic(err)
+	}
+
+	return value
+}
+
+func (e *Environment) assign(name Token, value Object) {
+	_, ok := e.values[name.lexeme]
+	if !ok {
+
+		if e.enclosing != nil {
+			e.enclosing.assign(name, value)
+			return
+		}
+
+		var err error = &RuntimeError{
+			Message: "Undefined variable '" + name.lexeme + "'.",
+			Token:   name,
+		}
+		panic(err)
+	}
+
+	e.values[name.lexeme] = value
+	e.initialized[name.lexeme] = true
+}
+
+// the top-level scope of the script or module the environment belongs to,
+// the last one before the builtins that end every chain
+func (e *Environment) globals() *Environment {
+	env := e
+	for env.enclosing != nil && env.enclosing.enclosing != nil {
+		env = env.enclosing
+	}
+	return env
+}
+
+// returns the environment exactly distance hops up the enclosing chain
+func (e *Environment) ancestor(distance int) *Environment {
+	env := e
+	for range distance {
+		env = env.enclosing
+	}
+	return env
+}
+
+// gets a variable the resolver has already located distance scopes away
+func (e *Environment) getAt(distance int, name Token) Object {
+	env := e.ancestor(distance)
+
+	if !env.initialized[name.lexeme] {
+		var err error = &RuntimeError{
+			Message: "Uninitialized variable '" + name.lexeme + "'.",
+			Token:   name,
+		}
+		panic(err)
+	}
+
+	return env.values[name.lexeme]
+}
+
+// assigns to a variable the resolver has already located distance scopes away
+func (e *Environment) assignAt(distance int, name Token, value Object) {
+	env := e.ancestor(distance)
+	env.values[name.lexeme] = value
+	env.initialized[name.lexeme] = true
+}
@@ -0,0 +1,202 @@
+package lox
+
+type exprVisitor interface {
+	VisitAssignExpr(expr *Assign) any
+	VisitBinaryExpr(expr *Binary) any
+	VisitCallExpr(expr *Call) any
+	VisitGetExpr(expr *Get) any
+	VisitGroupingExpr(expr *Grouping) any
+	VisitIndexExpr(expr *Index) any
+	VisitInterpolationExpr(expr *Interpolation) any
+	VisitLambdaExpr(expr *Lambda) any
+	VisitLiteralExpr(expr *Literal) any
+	VisitListExpr(expr *List) any
+	VisitLogicalExpr(expr *Logical) any
+	VisitMapExpr(expr *Map) any
+	VisitSetExpr(expr *Set) any
+	VisitSetIndexExpr(expr *SetIndex) any
+	VisitThisExpr(expr *This) any
+	VisitUnaryExpr(expr *Unary) any
+	VisitTernaryExpr(expr *Ternary) any
+	VisitCommaExpr(expr *Comma) any
+	VisitVariableExpr(expr *Variable) any
+}
+
+type Expr interface {
+	Accept(visitor exprVisitor) any
+}
+type Assign struct {
+	name  Token
+	value Expr
+	depth int
+}
+
+func (a *Assign) Accept(visitor exprVisitor) any {
+	return visitor.VisitAssignExpr(a)
+}
+
+type Binary struct {
+	left     Expr
+	operator Token
+	right    Expr
+}
+
+func (b *Binary) Accept(visitor exprVisitor) any {
+	return visitor.VisitBinaryExpr(b)
+}
+
+type Call struct {
+	callee    Expr
+	paren     Token
+	arguments []Expr
+}
+
+func (c *Call) Accept(visitor exprVisitor) any {
+	return visitor.VisitCallExpr(c)
+}
+
+type Get struct {
+	object Expr
+	name   Token
+}
+
+func (g *Get) Accept(visitor exprVisitor) any {
+	return visitor.VisitGetExpr(g)
+}
+
+type Grouping struct {
+	expression Expr
+}
+
+func (g *Grouping) Accept(visitor exprVisitor) any {
+	return visitor.VisitGroupingExpr(g)
+}
+
+type Index struct {
+	object  Expr
+	bracket Token
+	index   Expr
+}
+
+func (i *Index) Accept(visitor exprVisitor) any {
+	return visitor.VisitIndexExpr(i)
+}
+
+type Interpolation struct {
+	parts []Expr
+}
+
+func (i *Interpolation) Accept(visitor exprVisitor) any {
+	return visitor.VisitInterpolationExpr(i)
+}
+
+type Lambda struct {
+	function *Function
+}
+
+func (l *Lambda) Accept(visitor exprVisitor) any {
+	return visitor.VisitLambdaExpr(l)
+}
+
+type Literal struct {
+	value Object
+}
+
+func (l *Literal) Accept(visitor exprVisitor) any {
+	return visitor.VisitLiteralExpr(l)
+}
+
+type List struct {
+	bracket  Token
+	elements []Expr
+}
+
+func (l *List) Accept(visitor exprVisitor) any {
+	return visitor.VisitListExpr(l)
+}
+
+type Logical struct {
+	left     Expr
+	operator Token
+	right    Expr
+}
+
+func (l *Logical) Accept(visitor exprVisitor) any {
+	return visitor.VisitLogicalExpr(l)
+}
+
+type Map struct {
+	brace  Token
+	keys   []Expr
+	values []Expr
+}
+
+func (m *Map) Accept(visitor exprVisitor) any {
+	return visitor.VisitMapExpr(m)
+}
+
+type Set struct {
+	object Expr
+	name   Token
+	value  Expr
+}
+
+func (s *Set) Accept(visitor exprVisitor) any {
+	return visitor.VisitSetExpr(s)
+}
+
+type SetIndex struct {
+	object  Expr
+	bracket Token
+	index   Expr
+	value   Expr
+}
+
+func (s *SetIndex) Accept(visitor exprVisitor) any {
+	return visitor.VisitSetIndexExpr(s)
+}
+
+type This struct {
+	keyword Token
+	depth   int
+}
+
+func (t *This) Accept(visitor exprVisitor) any {
+	return visitor.VisitThisExpr(t)
+}
+
+type Unary struct {
+	operator Token
+	right    Expr
+}
+
+func (u *Unary) Accept(visitor exprVisitor) any {
+	return visitor.VisitUnaryExpr(u)
+}
+
+type Ternary struct {
+	condition Expr
+	outcome1  Expr
+	outcome2  Expr
+}
+
+func (t *Ternary) Accept(visitor exprVisitor) any {
+	return visitor.VisitTernaryExpr(t)
+}
+
+type Comma struct {
+	exprs []Expr
+}
+
+func (c *Comma) Accept(visitor exprVisitor) any {
+	return visitor.VisitCommaExpr(c)
+}
+
+type Variable struct {
+	name  Token
+	depth int
+}
+
+func (v *Variable) Accept(visitor exprVisitor) any {
+	return visitor.VisitVariableExpr(v)
+}
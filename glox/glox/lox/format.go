@@ -0,0 +1,349 @@
+package lox
+
+import (
+	"slices"
+	"strings"
+)
+
+// Format lays the source out the canonical way: four spaces of indentation,
+// opening braces at the end of the line, one statement per line and single
+// spaces around operators, comments are kept where they were, line breaks
+// inside statements and single blank lines between them too
+//
+// a source that does not parse is not formatted, its errors are returned
+func Format(source string) (string, error) {
+	scanner := NewScanner(source)
+	tokens := scanner.scanTokens()
+	parser := NewParser(tokens)
+	parser.Parse()
+
+	errs := slices.Concat(scanner.errors, parser.errors)
+	if len(errs) > 0 {
+		errs.sort()
+		return "", errs
+	}
+
+	// comments go between the tokens they were found between
+	items := slices.Concat(tokens[:len(tokens)-1], scanner.comments)
+	slices.SortFunc(items, func(a, b Token) int {
+		return a.offset - b.offset
+	})
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	f := newFormatter(items)
+	for i := range items {
+		f.write(i)
+	}
+	f.out.WriteString("\n")
+	return f.out.String(), nil
+}
+
+type frameKind int
+
+const (
+	// holds statements: the program, blocks and the bodies of classes and functions
+	frameBlock frameKind = iota
+	frameParen
+	frameBracket
+	frameMap
+	frameInterpolation
+)
+
+// frame is an open pair of brackets of any kind
+type frame struct {
+	kind frameKind
+
+	// indentation of the line the frame was opened on, the closing bracket
+	// goes back to it
+	indent int
+
+	// anonymous function written on one line, it stays on one line
+	inline bool
+
+	// the body of an anonymous function ends inside an expression
+	lambda bool
+
+	// a statement of the block has started and not ended yet, the lines it
+	// goes on to are indented further
+	inStatement bool
+
+	// ternaries in the frame waiting for their ':'
+	ternaries int
+}
+
+type formatter struct {
+	items []Token
+
+	// index of the closing brace of every opening brace
+	closers map[int]int
+
+	out strings.Builder
+
+	frames []*frame
+
+	// indentation of the current line
+	indent int
+
+	// the item written last, comments included
+	prev Token
+
+	// the token written last, comments excluded, and the one before it
+	prevCode     Token
+	prevPrevCode Token
+
+	// whether the last token ended an operand, a '-' after one is binary
+	prevOperand bool
+	prevUnary   bool
+
+	// the last token opened a frame
+	prevOpener bool
+
+	// the last token must end the line, a comment after it on the same
+	// line is written first
+	breakNext bool
+
+	// a 'fun' without a name was written, its body is the next block
+	lambda bool
+}
+
+func newFormatter(items []Token) *formatter {
+	f := &formatter{
+		items:   items,
+		closers: make(map[int]int, 16),
+		frames:  []*frame{{kind: frameBlock, indent: -1}},
+	}
+
+	var opened []int
+	for i, item := range items {
+		switch item.tokenType {
+		case LEFT_BRACE:
+			opened = append(opened, i)
+		case RIGHT_BRACE:
+			f.closers[opened[len(opened)-1]] = i
+			opened = opened[:len(opened)-1]
+		}
+	}
+	return f
+}
+
+func (f *formatter) top() *frame {
+	return f.frames[len(f.frames)-1]
+}
+
+// the line the item ends on, strings and comments may span several
+func lastLine(item Token) int {
+	return item.line + strings.Count(item.lexeme, "\n")
+}
+
+// the string after an interpolated expression, it starts with the '}'
+func continuesString(item Token) bool {
+	return (item.tokenType == STRING || item.tokenType == INTERPOLATION) && strings.HasPrefix(item.lexeme, "}")
+}
+
+func isLineComment(item Token) bool {
+	return item.tokenType == COMMENT && strings.HasPrefix(item.lexeme, "//")
+}
+
+// whether the opening brace starts the block of a statement like if or fun,
+// it goes on the line of the statement
+func (f *formatter) attachedBlock() bool {
+	switch f.prevCode.tokenType {
+	case RIGHT_PAREN, ELSE, TRY, FINALLY:
+		return true
+	case IDENTIFIER:
+		return f.prevPrevCode.tokenType == CLASS
+	}
+	return false
+}
+
+func (f *formatter) write(i int) {
+	item := f.items[i]
+	top := f.top()
+	closes := item.tokenType == RIGHT_PAREN || item.tokenType == RIGHT_BRACKET ||
+		item.tokenType == RIGHT_BRACE || continuesString(item)
+	attached := item.tokenType == LEFT_BRACE && f.attachedBlock()
+
+	// any other brace is a map unless it starts a statement
+	opensBlock := attached || item.tokenType == LEFT_BRACE && top.kind == frameBlock && !top.inStatement
+	closesBlock := item.tokenType == RIGHT_BRACE && top.kind == frameBlock
+
+	if f.out.Len() > 0 {
+		breaks := item.line - lastLine(f.prev)
+		emptyBlock := closesBlock && f.prevOpener && f.prevCode.tokenType == LEFT_BRACE && f.prev.tokenType != COMMENT
+
+		newline := false
+		switch {
+		case isLineComment(f.prev):
+			newline = true
+		case item.tokenType == COMMENT:
+			// a comment on the line of the last token stays there
+			newline = breaks > 0
+		case attached || item.tokenType == SEMICOLON:
+			newline = false
+		case (item.tokenType == ELSE || item.tokenType == CATCH || item.tokenType == FINALLY) &&
+			f.prevCode.tokenType == RIGHT_BRACE && f.prev.tokenType != COMMENT:
+			newline = false
+		case closesBlock && !top.inline:
+			newline = !emptyBlock
+		default:
+			newline = f.breakNext || breaks > 0
+		}
+
+		if newline {
+			f.out.WriteString("\n")
+			if breaks > 1 && !f.prevOpener && !closes {
+				f.out.WriteString("\n")
+			}
+
+			f.indent = top.indent + 1
+			if closes {
+				f.indent = top.indent
+			} else if top.kind == frameBlock && top.inStatement {
+				f.indent++
+			}
+			f.out.WriteString(strings.Repeat("    ", f.indent))
+		} else if f.spaced(item, closesBlock && !emptyBlock) {
+			f.out.WriteString(" ")
+		}
+
+		if item.tokenType != COMMENT || newline {
+			f.breakNext = false
+		}
+	}
+
+	if item.tokenType == COMMENT {
+		f.out.WriteString(strings.TrimRight(item.lexeme, " \t\r"))
+		f.prev = item
+		f.prevOpener = false
+		if isLineComment(item) {
+			f.breakNext = true
+		}
+		return
+	}
+
+	f.out.WriteString(item.lexeme)
+	f.update(i, item, opensBlock)
+}
+
+// whether a space goes between the last item and the item on the same line
+func (f *formatter) spaced(item Token, closesBlock bool) bool {
+	if f.prev.tokenType == COMMENT || item.tokenType == COMMENT {
+		return true
+	}
+
+	switch item.tokenType {
+	case RIGHT_PAREN, RIGHT_BRACKET, COMMA, SEMICOLON, DOT:
+		return false
+	case RIGHT_BRACE:
+		return closesBlock
+	case LEFT_PAREN, LEFT_BRACKET:
+		// calls and indexing
+		if f.prevOperand {
+			return false
+		}
+	case COLON:
+		// maps have no space before the colon, ternaries do
+		return f.top().ternaries > 0
+	}
+
+	if continuesString(item) || f.prevUnary {
+		return false
+	}
+
+	switch f.prev.tokenType {
+	case LEFT_PAREN, LEFT_BRACKET, DOT, INTERPOLATION:
+		return false
+	case LEFT_BRACE:
+		return f.prevOpener && f.frames[len(f.frames)-1].kind == frameBlock
+	}
+	return true
+}
+
+// keeps track of the frames and the statements after writing the token
+func (f *formatter) update(i int, item Token, opensBlock bool) {
+	top := f.top()
+	operand := false
+	unary := false
+	opener := false
+
+	switch item.tokenType {
+	case LEFT_PAREN, LEFT_BRACKET, LEFT_BRACE:
+		fr := &frame{kind: frameParen, indent: f.indent, inline: top.inline}
+		switch {
+		case item.tokenType == LEFT_BRACKET:
+			fr.kind = frameBracket
+		case opensBlock:
+			fr.kind = frameBlock
+			if f.lambda {
+				fr.lambda = true
+				fr.inline = fr.inline || item.line == f.items[f.closers[i]].line
+				f.lambda = false
+			}
+			f.breakNext = !fr.inline
+		case item.tokenType == LEFT_BRACE:
+			fr.kind = frameMap
+		}
+		if top.kind == frameBlock && !opensBlock {
+			top.inStatement = true
+		}
+		f.frames = append(f.frames, fr)
+		opener = true
+
+	case RIGHT_PAREN, RIGHT_BRACKET, RIGHT_BRACE:
+		f.frames = f.frames[:len(f.frames)-1]
+		operand = top.kind != frameBlock || top.lambda
+		if top.kind == frameBlock && !top.lambda {
+			f.top().inStatement = false
+			f.breakNext = !top.inline
+		}
+
+	case INTERPOLATION:
+		if continuesString(item) {
+			f.frames = f.frames[:len(f.frames)-1]
+		}
+		f.frames = append(f.frames, &frame{kind: frameInterpolation, indent: f.indent, inline: top.inline})
+		opener = true
+
+	case STRING:
+		if continuesString(item) {
+			f.frames = f.frames[:len(f.frames)-1]
+		}
+		operand = true
+
+	case SEMICOLON:
+		if top.kind == frameBlock {
+			top.inStatement = false
+			f.breakNext = !top.inline
+		}
+
+	case QUESTION_MARK:
+		top.ternaries++
+
+	case COLON:
+		if top.ternaries > 0 {
+			top.ternaries--
+		}
+
+	case FUN:
+		f.lambda = f.items[i+1].tokenType == LEFT_PAREN
+
+	case IDENTIFIER, NUMBER, TRUE, FALSE, NIL, THIS, SUPER:
+		operand = true
+
+	case MINUS, BANG:
+		unary = !f.prevOperand
+	}
+
+	if top.kind == frameBlock && item.tokenType != SEMICOLON && item.tokenType != RIGHT_BRACE && !opensBlock {
+		top.inStatement = true
+	}
+
+	f.prev = item
+	f.prevPrevCode = f.prevCode
+	f.prevCode = item
+	f.prevOperand = operand
+	f.prevUnary = unary
+	f.prevOpener = opener
+}
@@ -0,0 +1,84 @@
+package lox
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"print 1+2 ;", "print 1 + 2;\n"},
+		{"if(a){print a;}else{print -a;}", "if (a) {\n    print a;\n} else {\n    print -a;\n}\n"},
+		{"fun f(a,b)\n{\nreturn a-b; // difference\n}", "fun f(a, b) {\n    return a - b; // difference\n}\n"},
+		{"var m = { \"a\" : x ? 1 : 2 };", "var m = {\"a\": x ? 1 : 2};\n"},
+		{"var a;\n\n\n\n/* b */\nvar b;", "var a;\n\n/* b */\nvar b;\n"},
+		{"apply(fun (x) { return x; }, xs[0]);", "apply(fun (x) { return x; }, xs[0]);\n"},
+		{"var f = fun(x){\nreturn x;};", "var f = fun (x) {\n    return x;\n};\n"},
+		{"var total = 1 +\n2;", "var total = 1 +\n    2;\n"},
+		{"class A{}", "class A {}\n"},
+		{"print \"${ a+1 }!\";", "print \"${a + 1}!\";\n"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		actual, err := Format(test.source)
+		if err != nil {
+			t.Errorf("%q: %v", test.source, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("%q: expected\n%s\ngot\n%s", test.source, test.expected, actual)
+		}
+	}
+}
+
+func TestFormatRejectsErrors(t *testing.T) {
+	if _, err := Format("print (1;"); err == nil {
+		t.Error("expected the parse error")
+	}
+}
+
+// formatting changes nothing but whitespace, and formatting twice changes nothing
+func TestFormatTestFiles(t *testing.T) {
+	files, err := filepath.Glob("../../tests/*.lox")
+	if err != nil || len(files) == 0 {
+		t.Fatal("no test files", err)
+	}
+
+	lexemes := func(source string) []string {
+		scanner := NewScanner(source)
+		var result []string
+		for _, token := range slices.Concat(scanner.scanTokens(), scanner.comments) {
+			result = append(result, token.lexeme)
+		}
+		return result
+	}
+
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		formatted, err := Format(string(source))
+		if err != nil {
+			// some scripts test the errors they have
+			if _, statErr := os.Stat(strings.TrimSuffix(file, ".lox") + ".err"); statErr != nil {
+				t.Errorf("%s: %v", file, err)
+			}
+			continue
+		}
+		if !slices.Equal(lexemes(string(source)), lexemes(formatted)) {
+			t.Errorf("%s: formatting changed the tokens", file)
+		}
+		if again, _ := Format(formatted); again != formatted {
+			t.Errorf("%s: formatting again changed\n%s\ninto\n%s", file, formatted, again)
+		}
+	}
+}
@@ -0,0 +1,883 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RuntimeError stops a running program, it is raised as a panic while running
+// and returned from Run and Eval
+type RuntimeError struct {
+	Message string
+	Token   Token
+
+	// the calls that were running when the error happened, innermost first
+	Trace []StackFrame
+
+	// the value given to throw, nil for errors raised by the program itself
+	Value  Value
+	thrown bool
+}
+
+// StackFrame is one entry of the traceback of a RuntimeError, the position
+// execution had reached in the named function
+type StackFrame struct {
+	Function string
+	Line     int
+	Column   int
+
+	// empty for the source given to Run or Eval
+	File string
+}
+
+func (e *RuntimeError) Error() string {
+	if len(e.Trace) <= 1 {
+		return e.Message + "\n[line " + strconv.Itoa(e.Token.line) + "]"
+	}
+
+	lines := formatTrace(e.Trace, func(frame StackFrame) string {
+		return "[line " + strconv.Itoa(frame.Line) + "] in " + frame.Function
+	})
+	return e.Message + "\n" + strings.Join(lines, "\n")
+}
+
+// Line is the line of the program the error happened on
+func (e *RuntimeError) Line() int {
+	return e.Token.line
+}
+
+// Column is the column of the program the error happened on, 0 when unknown
+func (e *RuntimeError) Column() int {
+	return e.Token.column
+}
+
+type Interpreter struct {
+	environment *Environment
+	globals     *Environment
+
+	// natives and whatever the embedder defined, the globals of the script
+	// and of every module are enclosed by them
+	builtins *Environment
+
+	// the modules imported so far
+	modules *moduleLoader
+
+	// the generator whose body this interpreter is running, nil outside of generators
+	generator *LoxGenerator
+
+	// the generators whose body started running and has not finished, shared
+	// with the interpreters of the generators
+	suspended *[]*LoxGenerator
+
+	// where print writes to
+	stdout io.Writer
+
+	// state of the I/O natives
+	io *ioNatives
+
+	// the calls being run, the innermost last
+	callStack []callFrame
+
+	// pauses before statements, nil unless the script is being debugged
+	debugger *Debugger
+}
+
+// callFrame is a call the interpreter is running
+type callFrame struct {
+	function string
+	call     Token
+
+	// the environment of the caller at the call
+	environment *Environment
+}
+
+func NewInterpreter() *Interpreter {
+	builtins := NewEnvironment(nil)
+	globals := NewEnvironment(builtins)
+
+	i := &Interpreter{
+		environment: globals,
+		globals:     globals,
+		builtins:    builtins,
+		suspended:   new([]*LoxGenerator),
+		stdout:      os.Stdout,
+		io:          newIONatives(),
+	}
+	i.modules = newModuleLoader(i.io)
+
+	defineNatives(i, i.io)
+	return i
+}
+
+func (i *Interpreter) Run(source string) error {
+	_, err := i.run(source, "", false)
+	return err
+}
+
+func (i *Interpreter) RunFile(path string) error {
+	return i.modules.runScript(path, func(source string) error {
+		_, err := i.run(source, path, false)
+		return err
+	})
+}
+
+func (i *Interpreter) Eval(source string) (Value, error) {
+	return i.run(source, "", true)
+}
+
+func (i *Interpreter) Define(name string, value Value) {
+	i.builtins.initialize(name)
+	i.builtins.define(name, value)
+}
+
+func (i *Interpreter) DefineNative(name string, arity int, function NativeFunc) {
+	i.Define(name, &NativeFunction{name, arity, function})
+}
+
+func (i *Interpreter) SetOutput(w io.Writer) {
+	i.stdout = w
+}
+
+func (i *Interpreter) SetInput(r io.Reader) {
+	i.io.setInput(r)
+}
+
+func (i *Interpreter) SetSandbox(sandbox Sandbox) {
+	i.io.sandbox = sandbox
+}
+
+func (i *Interpreter) run(source string, file string, eval bool) (Value, error) {
+	statements, err := parse(source, file)
+	if err != nil {
+		return nil, err
+	}
+	return i.Interpret(statements, eval)
+}
+
+// runs the resolved statements, with eval set the value of a final expression
+// statement is returned
+func (i *Interpreter) Interpret(statements []Stmt, eval bool) (value Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case *RuntimeError:
+				if len(r.Trace) == 0 {
+					r.Trace = i.traceback(r.Token)
+				}
+				value, err = nil, r
+			case CompileErrors:
+				// found in an imported module
+				value, err = nil, r
+			default:
+				if r != ErrStopped {
+					panic(r)
+				}
+				value, err = nil, ErrStopped
+			}
+			i.callStack = i.callStack[:0]
+		}
+	}()
+
+	for index, stmt := range statements {
+		if exprStmt, ok := stmt.(*Expression); ok && eval && index == len(statements)-1 {
+			return i.evaluate(exprStmt.expression), nil
+		}
+		i.execute(stmt)
+	}
+	return nil, nil
+}
+
+// tracks a generator whose body is about to start, the ones that finished
+// since are dropped whenever the list has to grow
+func (i *Interpreter) suspend(generator *LoxGenerator) {
+	if len(*i.suspended) == cap(*i.suspended) {
+		*i.suspended = slices.DeleteFunc(*i.suspended, func(g *LoxGenerator) bool { return g.finished })
+	}
+	*i.suspended = append(*i.suspended, generator)
+}
+
+// Close stops the bodies of the suspended generators so that their goroutines
+// end, the generators count as finished afterwards
+func (i *Interpreter) Close() {
+	for _, generator := range *i.suspended {
+		generator.finish()
+	}
+	*i.suspended = (*i.suspended)[:0]
+}
+
+func (i *Interpreter) execute(stmt Stmt) *Completion {
+	if i.debugger != nil {
+		i.debugger.before(i, stmt)
+	}
+	completion, _ := stmt.Accept(i).(*Completion)
+	return completion
+}
+
+func (i *Interpreter) VisitBlockStmt(stmt *Block) any {
+	return i.executeBlock(stmt.statements, NewEnvironment(i.environment))
+}
+
+// executes the statements in env, stops at the first one that does not complete normally
+func (i *Interpreter) executeBlock(stmts []Stmt, env *Environment) *Completion {
+
+	oldEnv := i.environment
+
+	i.environment = env
+
+	// restored even when a runtime error unwinds through the block
+	defer func() {
+		i.environment = oldEnv
+	}()
+
+	for _, stmt := range stmts {
+		if completion := i.execute(stmt); completion != nil {
+			return completion
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitVarStmt(stmt *Var) any {
+	var value Object
+
+	if stmt.initializer != nil {
+		value = i.evaluate(stmt.initializer)
+		i.environment.initialize(stmt.name.lexeme)
+	}
+
+	i.environment.define(stmt.name.lexeme, value)
+	return nil
+}
+
+func (i *Interpreter) VisitExpressionStmt(stmt *Expression) any {
+	i.evaluate(stmt.expression)
+	return nil
+}
+
+func (i *Interpreter) VisitFunctionStmt(stmt *Function) any {
+	function := &LoxFunction{stmt, i.environment, false}
+	i.environment.initialize(stmt.name.lexeme)
+	i.environment.define(stmt.name.lexeme, function)
+	return nil
+}
+
+func (i *Interpreter) VisitLambdaExpr(expr *Lambda) any {
+	return &LoxFunction{expr.function, i.environment, false}
+}
+
+func (i *Interpreter) VisitClassStmt(stmt *Class) any {
+	i.environment.initialize(stmt.name.lexeme)
+	i.environment.define(stmt.name.lexeme, nil)
+
+	methods := make(map[string]*LoxFunction, len(stmt.methods))
+	for _, method := range stmt.methods {
+		isInitializer := method.name.lexeme == "init"
+		methods[method.name.lexeme] = &LoxFunction{method, i.environment, isInitializer}
+	}
+
+	class := NewLoxClass(stmt.name.lexeme, methods)
+	i.environment.define(stmt.name.lexeme, class)
+	return nil
+}
+
+func (i *Interpreter) VisitPrintStmt(stmt *Print) any {
+	value := i.evaluate(stmt.expression)
+	fmt.Fprintln(i.stdout, stringify(value))
+	return nil
+}
+
+func (i *Interpreter) VisitReturnStmt(stmt *Return) any {
+
+	var emptyExpr Expr
+	var value any
+
+	if stmt.value != emptyExpr {
+		value = i.evaluate(stmt.value)
+	}
+
+	return &Completion{completionReturn, value}
+}
+
+func (i *Interpreter) VisitYieldStmt(stmt *Yield) any {
+	var value any
+	if stmt.value != nil {
+		value = i.evaluate(stmt.value)
+	}
+
+	i.generator.yield(value)
+	return nil
+}
+
+func (i *Interpreter) VisitImportStmt(stmt *Import) any {
+	module := i.importModule(stmt.path)
+	i.environment.initialize(stmt.name.lexeme)
+	i.environment.define(stmt.name.lexeme, module)
+	return nil
+}
+
+// runs the module the first time it is imported, its top-level code gets
+// globals of its own, later imports get the same namespace
+func (i *Interpreter) importModule(path Token) *LoxModule {
+	file, absolute, err := i.modules.locate(path.file, path.object.(string))
+	if err != nil {
+		panic(&RuntimeError{Message: err.Error(), Token: path})
+	}
+	if module, ok := i.modules.loaded[absolute]; ok {
+		return module
+	}
+
+	source, err := i.modules.begin(path.object.(string), file, absolute)
+	if err != nil {
+		panic(&RuntimeError{Message: err.Error(), Token: path})
+	}
+	defer i.modules.end()
+
+	statements, err := parse(source, file)
+	if err != nil {
+		panic(err)
+	}
+
+	globals := NewEnvironment(i.builtins)
+	enclosing := i.environment
+	i.environment = globals
+	defer func() {
+		i.environment = enclosing
+	}()
+
+	i.callStack = append(i.callStack, callFrame{"module", path, enclosing})
+	for _, stmt := range statements {
+		i.execute(stmt)
+	}
+	i.callStack = i.callStack[:len(i.callStack)-1]
+
+	module := &LoxModule{
+		name: moduleName(file),
+		path: absolute,
+		lookup: func(name string) (any, bool) {
+			value, ok := globals.values[name]
+			return value, ok && globals.initialized[name]
+		},
+	}
+	i.modules.loaded[absolute] = module
+	return module
+}
+
+func (i *Interpreter) VisitThrowStmt(stmt *Throw) any {
+	panic(thrownError(i.evaluate(stmt.value), stmt.keyword))
+}
+
+func (i *Interpreter) VisitTryStmt(stmt *Try) any {
+	completion, err := i.catch(func() *Completion {
+		return i.executeBlock(stmt.body, NewEnvironment(i.environment))
+	})
+
+	if err != nil && stmt.hasCatch {
+		env := NewEnvironment(i.environment)
+		env.initialize(stmt.catchName.lexeme)
+		env.define(stmt.catchName.lexeme, err.value())
+
+		completion, err = i.catch(func() *Completion {
+			return i.executeBlock(stmt.catchBody, env)
+		})
+	}
+
+	// runs after a return, break or continue as well, leaving the finally
+	// block that way discards the pending error
+	if finally := i.executeBlock(stmt.finallyBody, NewEnvironment(i.environment)); finally != nil {
+		return finally
+	}
+
+	if err != nil {
+		panic(err)
+	}
+	return completion
+}
+
+// runs the block, a runtime error raised inside of it is returned instead of
+// unwinding further, its traceback is taken before the calls are dropped
+func (i *Interpreter) catch(block func() *Completion) (completion *Completion, err *RuntimeError) {
+	depth := len(i.callStack)
+
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeErr, ok := r.(*RuntimeError)
+			if !ok {
+				panic(r)
+			}
+
+			if len(runtimeErr.Trace) == 0 {
+				runtimeErr.Trace = i.traceback(runtimeErr.Token)
+			}
+			i.callStack = i.callStack[:depth]
+			err = runtimeErr
+		}
+	}()
+
+	return block(), nil
+}
+
+func (i *Interpreter) VisitIfStmt(stmt *If) any {
+	if isTruthy(i.evaluate(stmt.condition)) {
+		return i.execute(stmt.thenBranch)
+	} else if stmt.elseBranch != nil {
+		return i.execute(stmt.elseBranch)
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitWhileStmt(stmt *While) any {
+	for isTruthy(i.evaluate(stmt.condition)) {
+		if completion := i.execute(stmt.body); completion != nil {
+			if completion.kind == completionBreak {
+				break
+			}
+			if completion.kind == completionReturn {
+				return completion
+			}
+		}
+
+		// also reached after a continue
+		if stmt.increment != nil {
+			i.evaluate(stmt.increment)
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitBreakStmt(stmt *Break) any {
+	return breakCompletion
+}
+
+func (i *Interpreter) VisitContinueStmt(stmt *Continue) any {
+	return continueCompletion
+}
+
+func (i *Interpreter) VisitVariableExpr(expr *Variable) any {
+	return i.lookUpVariable(expr.name, expr.depth)
+}
+
+func (i *Interpreter) lookUpVariable(name Token, depth int) Object {
+	if depth != globalDepth {
+		return i.environment.getAt(depth, name)
+	}
+	return i.environment.globals().get(name)
+}
+
+func (i *Interpreter) VisitLiteralExpr(expr *Literal) any {
+	return expr.value
+}
+
+func (i *Interpreter) VisitGroupingExpr(expr *Grouping) any {
+	return i.evaluate(expr.expression)
+}
+
+func (i *Interpreter) evaluate(expr Expr) any {
+	return expr.Accept(i)
+}
+
+func (i *Interpreter) VisitAssignExpr(expr *Assign) any {
+	value := i.evaluate(expr.value)
+
+	if expr.depth != globalDepth {
+		i.environment.assignAt(expr.depth, expr.name, value)
+	} else {
+		i.environment.globals().assign(expr.name, value)
+	}
+
+	return value
+}
+
+func (i *Interpreter) VisitLogicalExpr(expr *Logical) any {
+
+	left := i.evaluate(expr.left)
+
+	switch expr.operator.tokenType {
+	case OR:
+		if isTruthy(left) {
+			return left
+		}
+	case AND:
+		if !isTruthy(left) {
+			return left
+		}
+	}
+
+	return i.evaluate(expr.right)
+
+}
+
+func (i *Interpreter) VisitUnaryExpr(expr *Unary) any {
+
+	right := i.evaluate(expr.right)
+
+	switch expr.operator.tokenType {
+	case MINUS:
+		n, ok := right.(float64)
+		if !ok {
+			i.raiseNumberOperands(expr.operator, right)
+		}
+
+		return -n
+	case BANG:
+		return !isTruthy(right)
+	default:
+		fmt.Println("unknown operator should be unreachable", right)
+		return nil
+	}
+}
+
+func (i *Interpreter) raiseNumberOperands(operator Token, operands ...any) {
+	var message string
+	if len(operands) == 1 {
+		message = "Operand must be a number."
+	} else {
+		message = "Operands must be numbers."
+	}
+
+	var err error = &RuntimeError{
+		Message: message,
+		Token:   operator,
+	}
+
+	panic(err)
+}
+
+func (i *Interpreter) VisitBinaryExpr(expr *Binary) any {
+
+	left := i.evaluate(expr.left)
+	right := i.evaluate(expr.right)
+
+	switch expr.operator.tokenType {
+	case MINUS,
+		SLASH,
+		STAR,
+		PERCENT,
+		GREATER,
+		GREATER_EQUAL,
+		LESS,
+		LESS_EQUAL:
+
+		leftNumber, leftNumberOk := left.(float64)
+		rightNumber, rightNumberOk := right.(float64)
+
+		if !(leftNumberOk && rightNumberOk) {
+			i.raiseNumberOperands(expr.operator, left, right)
+			return nil
+		}
+
+		switch expr.operator.tokenType {
+		case MINUS:
+			return leftNumber - rightNumber
+		case SLASH:
+			if rightNumber == 0 {
+				var err error = &RuntimeError{
+					Message: "Cannot divide by zero.",
+					Token:   expr.operator,
+				}
+				panic(err)
+			}
+
+			return leftNumber / rightNumber
+		case PERCENT:
+			if rightNumber == 0 {
+				var err error = &RuntimeError{
+					Message: "Cannot divide by zero.",
+					Token:   expr.operator,
+				}
+				panic(err)
+			}
+
+			return math.Mod(leftNumber, rightNumber)
+		case STAR:
+			return leftNumber * rightNumber
+		case GREATER:
+			return leftNumber > rightNumber
+		case GREATER_EQUAL:
+			return leftNumber >= rightNumber
+		case LESS:
+			return leftNumber < rightNumber
+		case LESS_EQUAL:
+			return leftNumber <= rightNumber
+		default:
+			return nil
+		}
+	case PLUS:
+
+		if result, ok := add(left, right); ok {
+			return result
+		}
+
+		var err error = &RuntimeError{
+			Message: "Operands must be two numbers or strings and a number.",
+			Token:   expr.operator,
+		}
+		panic(err)
+	case BANG_EQUAL:
+		return !isEqual(left, right)
+	case EQUAL_EQUAL:
+		return isEqual(left, right)
+	default:
+		fmt.Println("Unreachable unknown operator:", expr.operator.lexeme)
+		var err RuntimeError = RuntimeError{
+			Message: "Unknown operator, should have failed in parsing.",
+			Token:   expr.operator,
+		}
+		panic(err)
+	}
+}
+
+func (i *Interpreter) VisitCallExpr(expr *Call) any {
+	callee := i.evaluate(expr.callee)
+
+	arguments := make([]any, 0, 5)
+	for _, argument := range expr.arguments {
+		arguments = append(arguments, i.evaluate(argument))
+	}
+
+	if native, ok := callee.(*NativeFunction); ok {
+		i.checkArity(native.arity, len(arguments), expr.paren)
+
+		value, err := native.function(arguments)
+		if err != nil {
+			panic(&RuntimeError{Message: err.Error(), Token: expr.paren})
+		}
+		return value
+	}
+
+	function, ok := callee.(LoxCallable)
+	if !ok {
+		var err RuntimeError = RuntimeError{
+			Message: "Can only call functions and classes.",
+			Token:   expr.paren,
+		}
+		panic(&err)
+	}
+
+	i.checkArity(function.arity(), len(arguments), expr.paren)
+
+	// the script counts as a frame, as it does on the VM
+	if len(i.callStack) == maxFrames-1 {
+		panic(&RuntimeError{Message: "Stack overflow.", Token: expr.paren})
+	}
+
+	// popped only when the call returns, so a runtime error leaves the
+	// stack as it was for the traceback
+	i.callStack = append(i.callStack, callFrame{callableName(function), expr.paren, i.environment})
+	value := function.call(i, arguments)
+	i.callStack = i.callStack[:len(i.callStack)-1]
+
+	return value
+}
+
+// the name a callable has in tracebacks
+func callableName(callable LoxCallable) string {
+	switch callable := callable.(type) {
+	case *LoxFunction:
+		return functionName(callable.declaration)
+	case *LoxClass:
+		return "init"
+	case generatorMethod:
+		return callable.name
+	default:
+		return callable.String()
+	}
+}
+
+// the traceback of an error raised at the token, the frames of the call stack
+// followed by those of whoever resumed the generator this interpreter runs
+func (i *Interpreter) traceback(at Token) []StackFrame {
+	trace := []StackFrame{{i.frameName(len(i.callStack) - 1), at.line, at.column, at.file}}
+	for depth := len(i.callStack) - 1; depth >= 0; depth-- {
+		call := i.callStack[depth].call
+		trace = append(trace, StackFrame{i.frameName(depth - 1), call.line, call.column, call.file})
+	}
+
+	// the innermost frame of the caller is the call to next, which the
+	// outermost frame of the generator already stands for
+	if i.generator != nil && i.generator.caller != nil {
+		trace = append(trace, i.generator.caller.traceback(Token{})[1:]...)
+	}
+	return trace
+}
+
+// the name of the function running at the depth of the call stack, below
+// the first call it is the script or the body of the generator
+func (i *Interpreter) frameName(depth int) string {
+	if depth >= 0 {
+		return i.callStack[depth].function
+	}
+	if i.generator != nil {
+		return functionName(i.generator.function.declaration)
+	}
+	return "script"
+}
+
+// the frames of the debugger, built like the traceback along with the
+// environment each frame runs in
+func (i *Interpreter) debugFrames(at Token) []DebugFrame {
+	frames := []DebugFrame{{i.frameName(len(i.callStack) - 1), at.file, at.line, at.column, i.environment}}
+	for depth := len(i.callStack) - 1; depth >= 0; depth-- {
+		call := i.callStack[depth]
+		frames = append(frames, DebugFrame{i.frameName(depth - 1), call.call.file, call.call.line, call.call.column, call.environment})
+	}
+
+	if i.generator != nil && i.generator.caller != nil {
+		frames = append(frames, i.generator.caller.debugFrames(Token{})[1:]...)
+	}
+	return frames
+}
+
+// the number of calls being run, those of whoever resumed the generator included
+func (i *Interpreter) depth() int {
+	depth := len(i.callStack)
+	if i.generator != nil && i.generator.caller != nil {
+		depth += i.generator.caller.depth()
+	}
+	return depth
+}
+
+func (i *Interpreter) checkArity(arity int, argCount int, paren Token) {
+	if argCount != arity {
+		var err RuntimeError = RuntimeError{
+			Message: "Expected " + strconv.Itoa(arity) + " argument but got " + strconv.Itoa(argCount) + ".",
+			Token:   paren,
+		}
+		panic(&err)
+	}
+}
+
+func (i *Interpreter) VisitGetExpr(expr *Get) any {
+	object := i.evaluate(expr.object)
+	if instance, ok := object.(*LoxInstance); ok {
+		return instance.get(expr.name)
+	}
+
+	if generator, ok := object.(*LoxGenerator); ok {
+		return generator.get(expr.name)
+	}
+
+	if module, ok := object.(*LoxModule); ok {
+		if value, ok := module.lookup(expr.name.lexeme); ok {
+			return value
+		}
+		panic(&RuntimeError{Message: "Undefined property '" + expr.name.lexeme + "'.", Token: expr.name})
+	}
+
+	if loxErr, ok := object.(*LoxError); ok {
+		if value, ok := loxErr.field(expr.name.lexeme); ok {
+			return value
+		}
+		panic(&RuntimeError{Message: "Undefined property '" + expr.name.lexeme + "'.", Token: expr.name})
+	}
+
+	var err error = &RuntimeError{
+		Message: "Only instances have properties.",
+		Token:   expr.name,
+	}
+	panic(err)
+}
+
+func (i *Interpreter) VisitSetExpr(expr *Set) any {
+	object := i.evaluate(expr.object)
+
+	instance, ok := object.(*LoxInstance)
+	if !ok {
+		var err error = &RuntimeError{
+			Message: "Only instances have fields.",
+			Token:   expr.name,
+		}
+		panic(err)
+	}
+
+	value := i.evaluate(expr.value)
+	instance.set(expr.name, value)
+	return value
+}
+
+// every part is converted to text the way print does it
+func (i *Interpreter) VisitInterpolationExpr(expr *Interpolation) any {
+	var text strings.Builder
+	for _, part := range expr.parts {
+		text.WriteString(stringify(i.evaluate(part)))
+	}
+	return text.String()
+}
+
+func (i *Interpreter) VisitListExpr(expr *List) any {
+	elements := make([]any, len(expr.elements))
+	for index, element := range expr.elements {
+		elements[index] = i.evaluate(element)
+	}
+	return NewLoxList(elements)
+}
+
+func (i *Interpreter) VisitMapExpr(expr *Map) any {
+	m := NewLoxMap()
+	for index := range expr.keys {
+		key := i.evaluate(expr.keys[index])
+		value := i.evaluate(expr.values[index])
+
+		if err := m.set(key, value); err != nil {
+			panic(&RuntimeError{Message: err.Error(), Token: expr.brace})
+		}
+	}
+	return m
+}
+
+func (i *Interpreter) VisitIndexExpr(expr *Index) any {
+	object := i.evaluate(expr.object)
+	index := i.evaluate(expr.index)
+
+	value, err := getIndex(object, index)
+	if err != nil {
+		panic(&RuntimeError{Message: err.Error(), Token: expr.bracket})
+	}
+	return value
+}
+
+func (i *Interpreter) VisitSetIndexExpr(expr *SetIndex) any {
+	object := i.evaluate(expr.object)
+	index := i.evaluate(expr.index)
+	value := i.evaluate(expr.value)
+
+	if err := setIndex(object, index, value); err != nil {
+		panic(&RuntimeError{Message: err.Error(), Token: expr.bracket})
+	}
+	return value
+}
+
+func (i *Interpreter) VisitThisExpr(expr *This) any {
+	return i.lookUpVariable(expr.keyword, expr.depth)
+}
+
+func (i *Interpreter) VisitCommaExpr(expr *Comma) any {
+	// fmt.Println("visiting comma")
+
+	for index, e := range expr.exprs {
+		res := i.evaluate(e)
+		if index == len(expr.exprs)-1 {
+			return res
+		}
+	}
+
+	fmt.Println("unreachable")
+	return nil
+}
+
+func (i *Interpreter) VisitTernaryExpr(expr *Ternary) any {
+	// fmt.Println("visiting ternary")
+
+	condition := i.evaluate(expr.condition)
+	if isTruthy(condition) {
+		return i.evaluate(expr.outcome1)
+	}
+	return i.evaluate(expr.outcome2)
+}
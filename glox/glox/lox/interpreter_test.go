@@ -0,0 +1,114 @@
+package lox
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBlockRestoresEnvironmentAfterRuntimeError(t *testing.T) {
+	i := NewInterpreter()
+
+	if err := i.Run("{ var a = 1; { var b = a + nil; } }"); err == nil {
+		t.Fatal("expected a runtime error")
+	}
+
+	if i.environment != i.globals {
+		t.Error("environment of the failed block was left active")
+	}
+}
+
+func TestTraceback(t *testing.T) {
+	source := "fun inner() { return nil * 2; }\n" +
+		"fun outer() {\n" +
+		"  inner();\n" +
+		"}\n" +
+		"outer();"
+
+	expected := "Operands must be numbers.\n" +
+		"[line 1] in inner\n" +
+		"[line 3] in outer\n" +
+		"[line 5] in script"
+
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		if err := engine.Run(source); err == nil || err.Error() != expected {
+			t.Errorf("%T: expected:\n%s\nactual:\n%v", engine, expected, err)
+		}
+	}
+}
+
+func TestGeneratorsOutliveTheRun(t *testing.T) {
+	source := `fun naturals() {
+		var n = 0;
+		try {
+			while (true) { yield n; n = n + 1; }
+		} finally {
+			print "finally";
+		}
+	}`
+
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		var out strings.Builder
+		engine.SetOutput(&out)
+		if err := engine.Run(source); err != nil {
+			t.Fatal(err)
+		}
+
+		// a generator started by one Eval goes on in the next ones
+		evals := []string{"var g = naturals();", "g.next();", "g.next();", "g.next();", "g.done();"}
+		var values []Value
+		for _, eval := range evals {
+			value, err := engine.Eval(eval)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, value)
+		}
+		if actual := fmt.Sprint(values); actual != "[<nil> 0 1 2 false]" {
+			t.Errorf("%T: expected the generator to go on, got %s", engine, actual)
+		}
+		engine.Close()
+	}
+}
+
+func TestCloseEndsUnfinishedGenerators(t *testing.T) {
+	i := NewInterpreter()
+	var out strings.Builder
+	i.SetOutput(&out)
+
+	source := `fun naturals() {
+		var n = 0;
+		try {
+			while (true) { yield n; n = n + 1; }
+		} finally {
+			print "finally";
+		}
+	}
+	var generators = [];
+	for (var n = 0; n < 100; n = n + 1) {
+		var g = naturals();
+		g.next();
+		push(generators, g);
+	}`
+
+	before := runtime.NumGoroutine()
+	if err := i.Run(source); err != nil {
+		t.Fatal(err)
+	}
+	i.Close()
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected the generators to end, %d goroutines left over", after-before)
+	}
+
+	// they are finished without running their code
+	if value, err := i.Eval("generators[0].done();"); err != nil || value != true {
+		t.Errorf("expected the generator to be done, got %v, %v", value, err)
+	}
+	if value, err := i.Eval("generators[0].next();"); err != nil || value != nil {
+		t.Errorf("expected nil from a finished generator, got %v, %v", value, err)
+	}
+	if out.String() != "" {
+		t.Errorf("expected no Lox code to run while finishing, got %q", out.String())
+	}
+}
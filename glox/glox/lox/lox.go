@@ -0,0 +1,179 @@
+// Package lox implements the lox language, a program can be run either on the
+// tree-walking Interpreter or compiled to bytecode and run on the VM.
+//
+// Both backends implement Engine, several of them can live in one process as
+// they share no state:
+//
+//	interpreter := lox.NewInterpreter()
+//	interpreter.DefineNative("double", 1, func(args []lox.Value) (lox.Value, error) {
+//		n, ok := args[0].(float64)
+//		if !ok {
+//			return nil, errors.New("Argument must be a number.")
+//		}
+//		return n * 2, nil
+//	})
+//	value, err := interpreter.Eval("double(21);")
+package lox
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Value is a lox value as seen from go, nil, bool, float64 and string map to
+// the lox nil, booleans, numbers and strings, anything else is a lox object
+// like a function or an instance and can only be passed back to lox
+type Value = any
+
+// NativeFunc is the go implementation of a native function, the arguments are
+// already checked against the arity, a returned error becomes a lox runtime error
+type NativeFunc func(arguments []Value) (Value, error)
+
+// Engine is implemented by both backends
+type Engine interface {
+	// Run runs the whole program, the error is either CompileErrors or a *RuntimeError,
+	// the modules it imports are looked up relative to the working directory
+	Run(source string) error
+
+	// RunFile runs the program in the file, the modules it imports are looked
+	// up relative to the directory of the file
+	RunFile(path string) error
+
+	// Eval runs the program like Run and returns the value of its last
+	// statement when that is an expression statement, nil otherwise
+	Eval(source string) (Value, error)
+
+	// Define creates or overwrites a global variable
+	Define(name string, value Value)
+
+	// DefineNative creates a global function implemented in go
+	DefineNative(name string, arity int, function NativeFunc)
+
+	// SetOutput redirects what print writes, os.Stdout by default
+	SetOutput(w io.Writer)
+
+	// SetInput redirects what the input native reads, os.Stdin by default
+	SetInput(r io.Reader)
+
+	// SetSandbox limits what the I/O natives can do, by default they can do
+	// anything the process can, scripts that are not trusted should at least
+	// run with DisableFiles or a Root
+	SetSandbox(sandbox Sandbox)
+
+	// Close ends the generators left unfinished by the programs run so far,
+	// their bodies stop without running any more code, an engine that is no
+	// longer used should be closed
+	Close()
+}
+
+// CompileError is an error found before the program runs, by the scanner,
+// the parser, the resolver or the compiler
+type CompileError struct {
+	Line   int
+	Column int
+
+	// number of characters of the offending lexeme, underlined by FormatError
+	Length int
+
+	// names the offending lexeme, " at 'x'", " at end" or empty
+	Where   string
+	Message string
+
+	// path of the file the error is in, empty for the source given to Run or
+	// Eval, imported modules are always files
+	File string
+}
+
+func (e *CompileError) Error() string {
+	return fmt.Sprintf("[line %d:%d] Error%s: %s", e.Line, e.Column, e.Where, e.Message)
+}
+
+// the error reported at a token, which is named unless it is the end of the file
+func newCompileError(token Token, message string) *CompileError {
+	err := &CompileError{
+		Line:    token.line,
+		Column:  token.column,
+		Length:  utf8.RuneCountInString(token.lexeme),
+		Where:   " at '" + token.lexeme + "'",
+		Message: message,
+		File:    token.file,
+	}
+	if token.tokenType == EOF {
+		err.Where = " at end"
+	}
+	return err
+}
+
+// CompileErrors are all the errors found in a program before running it, a
+// program with any of them does not run at all
+type CompileErrors []*CompileError
+
+func (e CompileErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ExitCode is the exit status of a script that stopped with the error, as in
+// sysexits.h it is 65 when the script did not compile and 70 when it failed
+// while running, 0 when there was no error
+func ExitCode(err error) int {
+	var compileErrors CompileErrors
+	var compileErr *CompileError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &compileErrors), errors.As(err, &compileErr):
+		return 65
+	}
+	return 70
+}
+
+// orders the errors by their position in the source
+func (e CompileErrors) sort() {
+	slices.SortStableFunc(e, func(a, b *CompileError) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+}
+
+// prints the parse tree of every program before it runs
+var printParseTree bool = false
+
+// prints the bytecode of every program the VM compiles
+var printBytecode bool = false
+
+// scans, parses and resolves the source read from the file
+func parse(source string, file string) ([]Stmt, error) {
+	scanner := NewScanner(source)
+	scanner.file = file
+	tokens := scanner.scanTokens()
+
+	parser := NewParser(tokens)
+	statements := parser.Parse()
+
+	errs := append(scanner.errors, parser.errors...)
+	if len(errs) > 0 {
+		errs.sort()
+		return nil, errs
+	}
+
+	resolver := NewResolver()
+	resolver.Resolve(statements)
+	if len(resolver.errors) > 0 {
+		return nil, resolver.errors
+	}
+
+	if printParseTree {
+		astPrinter := AstPrinter{}
+		fmt.Println(astPrinter.Print(statements))
+	}
+
+	return statements, nil
+}
@@ -0,0 +1,7 @@
+package lox
+
+type LoxCallable interface {
+	call(interpreter *Interpreter, arguments []any) any
+	arity() int
+	String() string
+}
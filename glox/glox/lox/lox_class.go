@@ -0,0 +1,39 @@
+package lox
+
+type LoxClass struct {
+	name    string
+	methods map[string]*LoxFunction
+}
+
+func NewLoxClass(name string, methods map[string]*LoxFunction) *LoxClass {
+	return &LoxClass{
+		name:    name,
+		methods: methods,
+	}
+}
+
+func (c *LoxClass) findMethod(name string) *LoxFunction {
+	return c.methods[name]
+}
+
+// calling a class creates a new instance and runs its initializer if it has one
+func (c *LoxClass) call(interpreter *Interpreter, arguments []any) any {
+	instance := NewLoxInstance(c)
+
+	if initializer := c.findMethod("init"); initializer != nil {
+		initializer.bind(instance).call(interpreter, arguments)
+	}
+
+	return instance
+}
+
+func (c *LoxClass) arity() int {
+	if initializer := c.findMethod("init"); initializer != nil {
+		return initializer.arity()
+	}
+	return 0
+}
+
+func (c *LoxClass) String() string {
+	return c.name
+}
@@ -0,0 +1,39 @@
+package lox
+
+// LoxError is the value a catch clause receives for an error raised by the
+// program itself rather than by throw, like a division by zero, it has the
+// message and the line of the error as properties
+type LoxError struct {
+	err *RuntimeError
+}
+
+func (e *LoxError) field(name string) (any, bool) {
+	switch name {
+	case "message":
+		return e.err.Message, true
+	case "line":
+		return float64(e.err.Token.line), true
+	}
+	return nil, false
+}
+
+func (e *LoxError) String() string {
+	return e.err.Message
+}
+
+// the value a catch clause binds for the error
+func (e *RuntimeError) value() any {
+	if e.thrown {
+		return e.Value
+	}
+	return &LoxError{e}
+}
+
+// the error raised by throw, a caught error that is thrown again is raised
+// unchanged so that it keeps its message and traceback
+func thrownError(value any, token Token) *RuntimeError {
+	if loxErr, ok := value.(*LoxError); ok {
+		return loxErr.err
+	}
+	return &RuntimeError{Message: stringify(value), Token: token, Value: value, thrown: true}
+}
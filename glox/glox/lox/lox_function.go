@@ -0,0 +1,62 @@
+package lox
+
+type LoxFunction struct {
+	declaration   *Function
+	closure       *Environment
+	isInitializer bool
+}
+
+func (f *LoxFunction) call(interpreter *Interpreter, arguments []any) any {
+	if f.declaration.isGenerator {
+		return NewLoxGenerator(f, interpreter, arguments)
+	}
+
+	completion := interpreter.executeBlock(f.declaration.body, f.environment(arguments))
+
+	if f.isInitializer {
+		return f.closure.getAt(0, thisToken)
+	}
+
+	if completion != nil && completion.kind == completionReturn {
+		return completion.value
+	}
+	return nil
+}
+
+// creates the environment of a single call with the parameters bound to the arguments
+func (f *LoxFunction) environment(arguments []any) *Environment {
+	env := NewEnvironment(f.closure)
+	for i := range len(f.declaration.params) {
+		env.initialize(f.declaration.params[i].lexeme)
+		env.define(f.declaration.params[i].lexeme, arguments[i])
+	}
+	return env
+}
+
+// returns a copy of the method whose closure has "this" bound to the instance
+func (f *LoxFunction) bind(instance *LoxInstance) *LoxFunction {
+	env := NewEnvironment(f.closure)
+	env.initialize("this")
+	env.define("this", instance)
+
+	return &LoxFunction{f.declaration, env, f.isInitializer}
+}
+
+func (f *LoxFunction) arity() int {
+	return len(f.declaration.params)
+}
+
+func (f *LoxFunction) String() string {
+	return "<fn " + functionName(f.declaration) + ">"
+}
+
+// the name a function is shown with, anonymous functions have an empty name
+func functionName(declaration *Function) string {
+	if declaration.name.lexeme == "" {
+		return "anonymous"
+	}
+	return declaration.name.lexeme
+}
+
+// token used to look up the bound instance of methods
+var thisToken = Token{tokenType: THIS, lexeme: "this"}
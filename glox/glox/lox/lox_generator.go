@@ -0,0 +1,176 @@
+package lox
+
+// generatorSignal is sent by the body of a generator every time it stops running,
+// either because it yielded a value or because it finished
+type generatorSignal struct {
+	value    any
+	finished bool
+
+	// a panic that escaped the body, re-raised in the goroutine that called next
+	panicked any
+}
+
+// LoxGenerator is the value returned by calling a function that contains a yield,
+// its body runs on its own goroutine which is suspended at every yield and only
+// resumed when next is called, so at most one of the two goroutines runs at a time
+//
+// a generator left unfinished keeps its goroutine until the interpreter that
+// started it is closed
+type LoxGenerator struct {
+	function    *LoxFunction
+	interpreter Interpreter
+	arguments   []any
+
+	started  bool
+	finished bool
+
+	// set while the body runs, it can not be resumed from inside itself
+	running bool
+
+	// the interpreter that last resumed the body, its calls end the traceback
+	// of an error raised in the body
+	caller *Interpreter
+
+	resume  chan struct{}
+	signals chan generatorSignal
+
+	// closed to make the suspended body unwind instead of resuming
+	finishing chan struct{}
+}
+
+// generatorFinished unwinds the body of a generator that is finished while
+// it is suspended, no Lox code runs while it does
+type generatorFinished struct{}
+
+func NewLoxGenerator(function *LoxFunction, interpreter *Interpreter, arguments []any) *LoxGenerator {
+	g := &LoxGenerator{
+		function:    function,
+		interpreter: *interpreter,
+		arguments:   arguments,
+		resume:      make(chan struct{}),
+		signals:     make(chan generatorSignal),
+		finishing:   make(chan struct{}),
+	}
+
+	// the body gets its own call stack, the frames of whoever resumes it are
+	// added to the traceback of an error raised in the body
+	g.interpreter.generator = g
+	g.interpreter.callStack = nil
+	return g
+}
+
+// runs the body until the next yield and returns the yielded value,
+// once the body has finished it returns nil
+func (g *LoxGenerator) next(caller *Interpreter) any {
+	if g.finished {
+		return nil
+	}
+	if g.running {
+		// reported at the call to next, which is no frame of its own on the VM
+		call := caller.callStack[len(caller.callStack)-1].call
+		panic(&RuntimeError{
+			Message: "Generator is already running.",
+			Token:   call,
+			Trace:   caller.traceback(call)[1:],
+		})
+	}
+	g.caller = caller
+	g.running = true
+
+	if !g.started {
+		g.started = true
+		caller.suspend(g)
+		go g.run()
+	} else {
+		g.resume <- struct{}{}
+	}
+
+	signal := <-g.signals
+	g.running = false
+	if signal.finished {
+		g.finished = true
+		if signal.panicked != nil {
+			panic(signal.panicked)
+		}
+	}
+
+	return signal.value
+}
+
+// body of the generator goroutine
+func (g *LoxGenerator) run() {
+	defer func() {
+		signal := generatorSignal{finished: true}
+
+		r := recover()
+		if _, finishing := r.(generatorFinished); r != nil && !finishing {
+			if runtimeErr, ok := r.(*RuntimeError); ok && len(runtimeErr.Trace) == 0 {
+				runtimeErr.Trace = g.interpreter.traceback(runtimeErr.Token)
+			}
+			signal.panicked = r
+		}
+
+		g.signals <- signal
+	}()
+
+	g.interpreter.executeBlock(g.function.declaration.body, g.function.environment(g.arguments))
+}
+
+// called from the body, hands the value to next and waits until it is called again
+func (g *LoxGenerator) yield(value any) {
+	g.signals <- generatorSignal{value: value}
+	select {
+	case <-g.resume:
+	case <-g.finishing:
+		panic(generatorFinished{})
+	}
+}
+
+// ends the suspended body and waits for its goroutine to stop
+func (g *LoxGenerator) finish() {
+	if !g.started || g.finished {
+		return
+	}
+	g.finished = true
+	close(g.finishing)
+	<-g.signals
+}
+
+func (g *LoxGenerator) get(name Token) Object {
+	switch name.lexeme {
+	case "next", "done":
+		return generatorMethod{g, name.lexeme}
+	}
+
+	var err error = &RuntimeError{
+		Message: "Undefined property '" + name.lexeme + "'.",
+		Token:   name,
+	}
+	panic(err)
+}
+
+func (g *LoxGenerator) String() string {
+	return "<generator " + functionName(g.function.declaration) + ">"
+}
+
+// generatorMethod is a method of a generator bound to it, "next" resumes the body
+// and "done" tells if the body has already finished
+type generatorMethod struct {
+	generator *LoxGenerator
+	name      string
+}
+
+func (m generatorMethod) call(interpreter *Interpreter, arguments []any) any {
+	if m.name == "done" {
+		return m.generator.finished
+	}
+	return m.generator.next(interpreter)
+}
+
+func (m generatorMethod) arity() int {
+	return 0
+}
+
+func (m generatorMethod) String() string {
+	return "<native fn>"
+}
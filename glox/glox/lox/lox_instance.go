@@ -0,0 +1,38 @@
+package lox
+
+type LoxInstance struct {
+	class  *LoxClass
+	fields map[string]Object
+}
+
+func NewLoxInstance(class *LoxClass) *LoxInstance {
+	return &LoxInstance{
+		class:  class,
+		fields: make(map[string]Object, 10),
+	}
+}
+
+// fields shadow methods, methods are bound to the instance they are accessed on
+func (i *LoxInstance) get(name Token) Object {
+	if value, ok := i.fields[name.lexeme]; ok {
+		return value
+	}
+
+	if method := i.class.findMethod(name.lexeme); method != nil {
+		return method.bind(i)
+	}
+
+	var err error = &RuntimeError{
+		Message: "Undefined property '" + name.lexeme + "'.",
+		Token:   name,
+	}
+	panic(err)
+}
+
+func (i *LoxInstance) set(name Token, value Object) {
+	i.fields[name.lexeme] = value
+}
+
+func (i *LoxInstance) String() string {
+	return i.class.name + " instance"
+}
@@ -0,0 +1,80 @@
+package lox
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// LoxList is the mutable list created by a list literal, like instances
+// lists are shared by reference
+type LoxList struct {
+	elements []any
+}
+
+func NewLoxList(elements []any) *LoxList {
+	return &LoxList{elements: elements}
+}
+
+// checks that the index is a whole number within the list
+func (l *LoxList) position(index any) (int, error) {
+	n, ok := index.(float64)
+	if !ok || n != math.Trunc(n) {
+		return 0, errors.New("List index must be an integer.")
+	}
+
+	if n < 0 || n >= float64(len(l.elements)) {
+		return 0, errors.New("List index out of range.")
+	}
+
+	return int(n), nil
+}
+
+// lists are equal when they have equal elements
+func (l *LoxList) equals(other *LoxList, comparing map[[2]any]bool) bool {
+	if l == other {
+		return true
+	}
+
+	if len(l.elements) != len(other.elements) {
+		return false
+	}
+
+	pair := [2]any{l, other}
+	if comparing[pair] {
+		return true
+	}
+	if comparing == nil {
+		comparing = make(map[[2]any]bool, 4)
+	}
+	comparing[pair] = true
+	defer delete(comparing, pair)
+
+	for i := range l.elements {
+		if !equal(l.elements[i], other.elements[i], comparing) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *LoxList) String() string {
+	return l.format(nil)
+}
+
+func (l *LoxList) format(formatting map[any]bool) string {
+	if formatting[l] {
+		return "[...]"
+	}
+	if formatting == nil {
+		formatting = make(map[any]bool, 4)
+	}
+	formatting[l] = true
+	defer delete(formatting, l)
+
+	elements := make([]string, len(l.elements))
+	for i, element := range l.elements {
+		elements[i] = formatElement(element, formatting)
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
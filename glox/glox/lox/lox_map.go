@@ -0,0 +1,130 @@
+package lox
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// LoxMap is the mutable map created by a map literal or Map(), its keys are
+// strings, numbers and booleans which are compared like isEqual compares them,
+// the keys are kept in the order they were first set
+type LoxMap struct {
+	keys   []any
+	values map[any]any
+}
+
+func NewLoxMap() *LoxMap {
+	return &LoxMap{values: make(map[any]any, 8)}
+}
+
+// only values that go compares the same way as isEqual can be keys, NaN is
+// not even equal to itself so it could never be found again
+func checkKey(key any) error {
+	switch key := key.(type) {
+	case float64:
+		if math.IsNaN(key) {
+			return errors.New("Map key can't be NaN.")
+		}
+		return nil
+	case string, bool:
+		return nil
+	default:
+		return errors.New("Map key must be a string, number or boolean.")
+	}
+}
+
+func (m *LoxMap) get(key any) (any, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	value, ok := m.values[key]
+	if !ok {
+		return nil, errors.New("Undefined key " + formatElement(key, nil) + ".")
+	}
+	return value, nil
+}
+
+func (m *LoxMap) set(key any, value any) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+	return nil
+}
+
+func (m *LoxMap) has(key any) bool {
+	_, ok := m.values[key]
+	return ok
+}
+
+// removes the key, returns false if it was not in the map
+func (m *LoxMap) delete(key any) bool {
+	if !m.has(key) {
+		return false
+	}
+
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// maps are equal when they have the same keys with equal values
+func (m *LoxMap) equals(other *LoxMap, comparing map[[2]any]bool) bool {
+	if m == other {
+		return true
+	}
+
+	if len(m.keys) != len(other.keys) {
+		return false
+	}
+
+	pair := [2]any{m, other}
+	if comparing[pair] {
+		return true
+	}
+	if comparing == nil {
+		comparing = make(map[[2]any]bool, 4)
+	}
+	comparing[pair] = true
+	defer delete(comparing, pair)
+
+	for key, value := range m.values {
+		otherValue, ok := other.values[key]
+		if !ok || !equal(value, otherValue, comparing) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *LoxMap) String() string {
+	return m.format(nil)
+}
+
+func (m *LoxMap) format(formatting map[any]bool) string {
+	if formatting[m] {
+		return "{...}"
+	}
+	if formatting == nil {
+		formatting = make(map[any]bool, 4)
+	}
+	formatting[m] = true
+	defer delete(formatting, m)
+
+	entries := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		entries[i] = formatElement(key, nil) + ": " + formatElement(m.values[key], formatting)
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
@@ -0,0 +1,214 @@
+package lox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		value, err := engine.Eval("var a = 20; a + 22;")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != 42.0 {
+			t.Errorf("%T: expected 42, got %v", engine, value)
+		}
+
+		value, err = engine.Eval("print a;")
+		if err != nil || value != nil {
+			t.Errorf("%T: expected nil without error, got %v, %v", engine, value, err)
+		}
+	}
+}
+
+func TestDefineNative(t *testing.T) {
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		engine.DefineNative("twice", 1, func(arguments []Value) (Value, error) {
+			s, ok := arguments[0].(string)
+			if !ok {
+				return nil, errors.New("Argument must be a string.")
+			}
+			return s + s, nil
+		})
+
+		var out strings.Builder
+		engine.SetOutput(&out)
+
+		if err := engine.Run(`print twice("ab");`); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "abab\n" {
+			t.Errorf("%T: expected abab, got %q", engine, out.String())
+		}
+
+		var runtimeErr *RuntimeError
+		err := engine.Run("\ntwice(1);")
+		if !errors.As(err, &runtimeErr) || runtimeErr.Message != "Argument must be a string." || runtimeErr.Line() != 2 {
+			t.Errorf("%T: expected the native error on line 2, got %v", engine, err)
+		}
+	}
+}
+
+func TestUncaughtThrow(t *testing.T) {
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		var runtimeErr *RuntimeError
+		err := engine.Run("try {\n  throw 42;\n} finally {}")
+		if !errors.As(err, &runtimeErr) || runtimeErr.Value != 42.0 || runtimeErr.Message != "42" || runtimeErr.Line() != 2 {
+			t.Errorf("%T: expected the thrown 42 on line 2, got %v", engine, err)
+		}
+
+		// errors raised by the program itself carry no value
+		err = engine.Run("1 / 0;")
+		if !errors.As(err, &runtimeErr) || runtimeErr.Value != nil {
+			t.Errorf("%T: expected an error without value, got %v", engine, err)
+		}
+	}
+}
+
+func TestEnginesAreIndependent(t *testing.T) {
+	first, second := NewInterpreter(), NewInterpreter()
+
+	if _, err := first.Eval("var a = 1;"); err != nil {
+		t.Fatal(err)
+	}
+
+	var compileErrors CompileErrors
+	if _, err := second.Eval("var = 2;"); !errors.As(err, &compileErrors) {
+		t.Errorf("expected compile errors, got %v", err)
+	}
+
+	if _, err := second.Eval("a;"); err == nil {
+		t.Error("variable leaked into another interpreter")
+	}
+
+	if value, err := first.Eval("a;"); err != nil || value != 1.0 {
+		t.Errorf("expected 1, got %v, %v", value, err)
+	}
+}
+
+func TestIONatives(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		var out strings.Builder
+		engine.SetOutput(&out)
+		engine.SetInput(strings.NewReader("first\r\nsecond"))
+		engine.SetSandbox(Sandbox{Root: dir})
+
+		err := engine.Run(`
+			writeFile("notes.txt", "a");
+			appendFile("/notes.txt", "b");
+			print readFile("notes.txt");
+			print listDir(".");
+			print exists("../notes.txt") and !exists("missing");
+			print input() + input();
+			print input();
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "ab\n[\"notes.txt\"]\ntrue\nfirstsecond\nnil\n" {
+			t.Errorf("%T: unexpected output %q", engine, out.String())
+		}
+
+		if err := engine.Run(`readFile("missing");`); err == nil || !strings.HasPrefix(err.Error(), "Can't read file 'missing'") {
+			t.Errorf("%T: expected a read error, got %v", engine, err)
+		}
+	}
+}
+
+func TestRunFileImportsRelativeToFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.lox":      `import "lib/a.lox"; print a.value;`,
+		"lib/a.lox":     `import "b.lox"; var value = b.value + 1;`,
+		"lib/b.lox":     `var value = 41;`,
+		"lib/cycle.lox": `import "cycle.lox";`,
+		"entry.lox":     `print "entry top"; import "lib/back.lox";`,
+		"lib/back.lox":  `import "../entry.lox";`,
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		var out strings.Builder
+		engine.SetOutput(&out)
+
+		if err := engine.RunFile(filepath.Join(dir, "main.lox")); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "42\n" {
+			t.Errorf("%T: expected 42, got %q", engine, out.String())
+		}
+
+		var runtimeErr *RuntimeError
+		err := engine.RunFile(filepath.Join(dir, "lib/cycle.lox"))
+		if !errors.As(err, &runtimeErr) || runtimeErr.Message != "Import cycle: cycle.lox -> cycle.lox." {
+			t.Errorf("%T: expected an import cycle, got %v", engine, err)
+		}
+
+		// the script that was run is part of the cycle and runs only once
+		out.Reset()
+		err = engine.RunFile(filepath.Join(dir, "entry.lox"))
+		if !errors.As(err, &runtimeErr) || runtimeErr.Message != "Import cycle: entry.lox -> lib/back.lox -> ../entry.lox." {
+			t.Errorf("%T: expected an import cycle through the script, got %v", engine, err)
+		}
+		if out.String() != "entry top\n" {
+			t.Errorf("%T: expected the script to run once, got %q", engine, out.String())
+		}
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Symlink(os.TempDir(), filepath.Join(dir, "link")); err != nil {
+		t.Skip(err)
+	}
+	outside := filepath.Join(t.TempDir(), "escaped.txt")
+	if err := os.Symlink(outside, filepath.Join(dir, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("loop", filepath.Join(dir, "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sandbox Sandbox
+		source  string
+		message string
+	}{
+		{Sandbox{DisableFiles: true}, `exists("x");`, "File access is disabled."},
+		{Sandbox{ReadOnly: true}, `writeFile("x", "");`, "Writing files is disabled."},
+		{Sandbox{DisableInput: true}, `input();`, "Reading input is disabled."},
+		{Sandbox{Root: dir}, `listDir("link/");`, "Path 'link/' is outside of the sandbox."},
+		{Sandbox{DisableFiles: true}, `import "x.lox";`, "Can't import 'x.lox': file access is disabled."},
+		{Sandbox{Root: dir}, `import "link/x.lox";`, "Path 'link/x.lox' is outside of the sandbox."},
+		{Sandbox{Root: dir}, `writeFile("dangling", "escaped");`, "Path 'dangling' is outside of the sandbox."},
+		{Sandbox{Root: dir}, `writeFile("loop", "");`, "Path 'loop' is outside of the sandbox."},
+	}
+
+	for _, engine := range []Engine{NewInterpreter(), NewVM()} {
+		for _, test := range tests {
+			engine.SetSandbox(test.sandbox)
+
+			var runtimeErr *RuntimeError
+			if err := engine.Run(test.source); !errors.As(err, &runtimeErr) || runtimeErr.Message != test.message {
+				t.Errorf("%s: expected %q, got %v", test.source, test.message, err)
+			}
+		}
+	}
+	if _, err := os.Stat(outside); err == nil {
+		t.Error("expected no file written through the dangling link")
+	}
+}
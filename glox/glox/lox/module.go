@@ -0,0 +1,138 @@
+package lox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoxModule is the namespace an import binds, its properties are the
+// top-level variables of the module
+type LoxModule struct {
+	name string
+
+	// absolute path of the file, modules are cached by it
+	path string
+
+	// looks up a top-level variable, the module sees later assignments to it
+	lookup func(name string) (any, bool)
+}
+
+func (m *LoxModule) String() string {
+	return "<module " + m.name + ">"
+}
+
+// the name an import binds when none is given, the file name without extension
+func moduleName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// moduleLoader finds, reads and caches the modules imported by one engine,
+// every module runs once no matter how often it is imported
+type moduleLoader struct {
+	// the sandbox of the file natives applies to imports too
+	io *ioNatives
+
+	// modules whose top-level code has finished, by absolute path
+	loaded map[string]*LoxModule
+
+	// modules whose top-level code is running, the innermost last
+	loading []loadingModule
+
+	// the files modules were read from, paths relative to them are
+	// relative to their directory even in a sandbox
+	files map[string]bool
+}
+
+type loadingModule struct {
+	// the path as written in the import, used in errors
+	path string
+
+	file     string
+	absolute string
+}
+
+func newModuleLoader(io *ioNatives) *moduleLoader {
+	return &moduleLoader{
+		io:     io,
+		loaded: make(map[string]*LoxModule, 8),
+		files:  make(map[string]bool, 8),
+	}
+}
+
+// finds the file of an import, a relative path starts from the directory of
+// the importing file, returns the path to report errors with and the absolute
+// path the module is cached by
+func (l *moduleLoader) locate(importer string, path string) (string, string, error) {
+	if l.io.sandbox.DisableFiles {
+		return "", "", errors.New("Can't import '" + path + "': file access is disabled.")
+	}
+
+	// in a sandbox the source given to Run counts as lying in the root
+	file := path
+	if !filepath.IsAbs(path) && (l.io.sandbox.Root == "" || l.files[importer]) {
+		file = filepath.Join(filepath.Dir(importer), path)
+	}
+
+	if l.io.sandbox.Root != "" {
+		absolute, err := confine(l.io.sandbox.Root, file)
+		return file, absolute, err
+	}
+
+	absolute, err := filepath.Abs(file)
+	if err != nil {
+		return "", "", fileError("import", path, err)
+	}
+	return file, absolute, nil
+}
+
+// runs the script in the file as the outermost loading module, so that a
+// module importing it back is an import cycle instead of a second run of it
+func (l *moduleLoader) runScript(path string, run func(source string) error) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	l.loading = []loadingModule{{filepath.Base(path), path, absolute}}
+	l.files[path] = true
+	defer func() { l.loading = nil }()
+
+	return run(string(source))
+}
+
+// reads the source of a module that is about to run, fails when the module is
+// already running as that means it imports itself through the modules it imports
+func (l *moduleLoader) begin(path string, file string, absolute string) (string, error) {
+	for i, module := range l.loading {
+		if module.absolute == absolute {
+			cycle := make([]string, 0, len(l.loading)-i+1)
+			for _, m := range l.loading[i:] {
+				cycle = append(cycle, m.path)
+			}
+			cycle = append(cycle, path)
+			return "", errors.New("Import cycle: " + strings.Join(cycle, " -> ") + ".")
+		}
+	}
+
+	source, err := os.ReadFile(absolute)
+	if err != nil {
+		return "", fileError("import", path, err)
+	}
+
+	l.loading = append(l.loading, loadingModule{path, file, absolute})
+	l.files[file] = true
+	return string(source), nil
+}
+
+// called once the top-level code of the innermost loading module stopped,
+// either because it finished or because an error escaped it
+func (l *moduleLoader) end() {
+	l.loading = l.loading[:len(l.loading)-1]
+}
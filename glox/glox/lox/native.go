@@ -0,0 +1,192 @@
+package lox
+
+import (
+	"errors"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// NativeFunction is a function implemented in go, see Engine.DefineNative
+type NativeFunction struct {
+	name     string
+	arity    int
+	function NativeFunc
+}
+
+func (n *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+// defines the natives and the constants as globals of the engine, natives
+// with state like the random number generator get their own for every engine
+func defineNatives(engine Engine, io *ioNatives) {
+	for _, native := range natives {
+		engine.DefineNative(native.name, native.arity, native.function)
+	}
+
+	engine.Define("pi", math.Pi)
+
+	random := newRandom()
+	engine.DefineNative("seed", 1, random.seed)
+	engine.DefineNative("random", 0, random.random)
+	engine.DefineNative("randomInt", 2, random.randomInt)
+
+	io.define(engine)
+}
+
+// natives are defined as globals of every Interpreter and VM
+var natives = []struct {
+	name     string
+	arity    int
+	function NativeFunc
+}{
+	{"clock", 0, clock},
+	{"len", 1, length},
+	{"push", 2, push},
+	{"pop", 1, pop},
+	{"slice", 3, slice},
+	{"Map", 0, newMap},
+	{"keys", 1, keys},
+	{"has", 2, has},
+	{"delete", 2, deleteKey},
+
+	{"substr", 3, substr},
+	{"indexOf", 2, indexOf},
+	{"split", 2, split},
+	{"join", 2, join},
+	{"upper", 1, upper},
+	{"lower", 1, lower},
+	{"trim", 1, trim},
+	{"replace", 3, replace},
+	{"startsWith", 2, startsWith},
+	{"endsWith", 2, endsWith},
+	{"chr", 1, chr},
+	{"ord", 1, ord},
+	{"toNumber", 1, toNumber},
+	{"toString", 1, toString},
+
+	{"sqrt", 1, sqrt},
+	{"pow", 2, pow},
+	{"floor", 1, floor},
+	{"ceil", 1, ceil},
+	{"round", 1, round},
+	{"abs", 1, abs},
+	{"min", 2, minimum},
+	{"max", 2, maximum},
+	{"sin", 1, sin},
+	{"cos", 1, cos},
+	{"tan", 1, tan},
+	{"log", 1, log},
+	{"exp", 1, exp},
+}
+
+func clock(arguments []Value) (Value, error) {
+	return float64(time.Now().UnixMicro()), nil
+}
+
+// the number of elements of a list, of entries of a map or of characters of a string
+func length(arguments []Value) (Value, error) {
+	switch value := arguments[0].(type) {
+	case *LoxList:
+		return float64(len(value.elements)), nil
+	case *LoxMap:
+		return float64(len(value.keys)), nil
+	case string:
+		return float64(utf8.RuneCountInString(value)), nil
+	default:
+		return nil, errors.New("Can only take the length of a list, a map or a string.")
+	}
+}
+
+// appends the value to the end of the list
+func push(arguments []Value) (Value, error) {
+	list, ok := arguments[0].(*LoxList)
+	if !ok {
+		return nil, errors.New("Can only push to a list.")
+	}
+
+	list.elements = append(list.elements, arguments[1])
+	return nil, nil
+}
+
+// removes the last element of the list and returns it
+func pop(arguments []Value) (Value, error) {
+	list, ok := arguments[0].(*LoxList)
+	if !ok {
+		return nil, errors.New("Can only pop from a list.")
+	}
+
+	if len(list.elements) == 0 {
+		return nil, errors.New("Can't pop from an empty list.")
+	}
+
+	last := list.elements[len(list.elements)-1]
+	list.elements = list.elements[:len(list.elements)-1]
+	return last, nil
+}
+
+// returns a new list with the elements from start up to but not including end
+func slice(arguments []Value) (Value, error) {
+	list, ok := arguments[0].(*LoxList)
+	if !ok {
+		return nil, errors.New("Can only slice a list.")
+	}
+
+	start, startOk := arguments[1].(float64)
+	end, endOk := arguments[2].(float64)
+	if !startOk || !endOk || start != math.Trunc(start) || end != math.Trunc(end) {
+		return nil, errors.New("Slice bounds must be integers.")
+	}
+
+	if start < 0 || end > float64(len(list.elements)) || start > end {
+		return nil, errors.New("Slice bounds out of range.")
+	}
+
+	elements := make([]any, int(end-start))
+	copy(elements, list.elements[int(start):int(end)])
+	return NewLoxList(elements), nil
+}
+
+// creates an empty map
+func newMap(arguments []Value) (Value, error) {
+	return NewLoxMap(), nil
+}
+
+// returns a list of the keys of the map in the order they were added
+func keys(arguments []Value) (Value, error) {
+	m, ok := arguments[0].(*LoxMap)
+	if !ok {
+		return nil, errors.New("Can only get the keys of a map.")
+	}
+
+	elements := make([]any, len(m.keys))
+	copy(elements, m.keys)
+	return NewLoxList(elements), nil
+}
+
+// tells if the key is in the map
+func has(arguments []Value) (Value, error) {
+	m, ok := arguments[0].(*LoxMap)
+	if !ok {
+		return nil, errors.New("Can only look up keys in a map.")
+	}
+
+	if err := checkKey(arguments[1]); err != nil {
+		return nil, err
+	}
+	return m.has(arguments[1]), nil
+}
+
+// removes the key from the map, returns false if it was not there
+func deleteKey(arguments []Value) (Value, error) {
+	m, ok := arguments[0].(*LoxMap)
+	if !ok {
+		return nil, errors.New("Can only delete keys from a map.")
+	}
+
+	if err := checkKey(arguments[1]); err != nil {
+		return nil, err
+	}
+	return m.delete(arguments[1]), nil
+}
@@ -0,0 +1,236 @@
+package lox
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sandbox limits what the I/O natives of an engine can do, the zero value
+// allows everything the process itself may do
+type Sandbox struct {
+	// input raises a runtime error instead of reading
+	DisableInput bool
+
+	// every file native raises a runtime error instead of touching the filesystem
+	DisableFiles bool
+
+	// writeFile and appendFile raise a runtime error
+	ReadOnly bool
+
+	// when set, paths are relative to this directory and can't leave it,
+	// not even through symbolic links
+	Root string
+}
+
+// ioNatives are the I/O natives of one engine, they share its input and sandbox
+type ioNatives struct {
+	stdin   *bufio.Reader
+	sandbox Sandbox
+}
+
+func newIONatives() *ioNatives {
+	return &ioNatives{stdin: bufio.NewReader(os.Stdin)}
+}
+
+func (n *ioNatives) define(engine Engine) {
+	engine.DefineNative("input", 0, n.input)
+	engine.DefineNative("readFile", 1, n.readFile)
+	engine.DefineNative("writeFile", 2, n.writeFile)
+	engine.DefineNative("appendFile", 2, n.appendFile)
+	engine.DefineNative("listDir", 1, n.listDir)
+	engine.DefineNative("exists", 1, n.exists)
+}
+
+func (n *ioNatives) setInput(r io.Reader) {
+	n.stdin = bufio.NewReader(r)
+}
+
+// reads a line without its line break, returns nil once the input has ended
+func (n *ioNatives) input(arguments []Value) (Value, error) {
+	if n.sandbox.DisableInput {
+		return nil, errors.New("Reading input is disabled.")
+	}
+
+	line, err := n.stdin.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return nil, nil
+	} else if err != nil && err != io.EOF {
+		return nil, errors.New("Can't read input: " + err.Error() + ".")
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+func (n *ioNatives) readFile(arguments []Value) (Value, error) {
+	path, err := n.path("readFile", arguments, false)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fileError("read file", arguments[0], err)
+	}
+	return string(content), nil
+}
+
+// replaces the content of the file with the text, creating the file if needed
+func (n *ioNatives) writeFile(arguments []Value) (Value, error) {
+	return n.write("writeFile", arguments, os.O_TRUNC)
+}
+
+// adds the text at the end of the file, creating the file if needed
+func (n *ioNatives) appendFile(arguments []Value) (Value, error) {
+	return n.write("appendFile", arguments, os.O_APPEND)
+}
+
+func (n *ioNatives) write(function string, arguments []Value, mode int) (Value, error) {
+	path, err := n.path(function, arguments, true)
+	if err != nil {
+		return nil, err
+	}
+	text, err := stringArgument(function, arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|mode, 0o644)
+	if err != nil {
+		return nil, fileError("write file", arguments[0], err)
+	}
+
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fileError("write file", arguments[0], err)
+	}
+	return nil, nil
+}
+
+// returns the sorted names of the entries of the directory
+func (n *ioNatives) listDir(arguments []Value) (Value, error) {
+	path, err := n.path("listDir", arguments, false)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fileError("list directory", arguments[0], err)
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	sort.Strings(names)
+
+	elements := make([]any, len(names))
+	for i, name := range names {
+		elements[i] = name
+	}
+	return NewLoxList(elements), nil
+}
+
+func (n *ioNatives) exists(arguments []Value) (Value, error) {
+	path, err := n.path("exists", arguments, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(path)
+	return err == nil, nil
+}
+
+// checks the sandbox and returns the path of the first argument to pass to os
+func (n *ioNatives) path(function string, arguments []Value, write bool) (string, error) {
+	if n.sandbox.DisableFiles {
+		return "", errors.New("File access is disabled.")
+	}
+	if write && n.sandbox.ReadOnly {
+		return "", errors.New("Writing files is disabled.")
+	}
+
+	path, err := stringArgument(function, arguments, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if n.sandbox.Root == "" {
+		return path, nil
+	}
+	return confine(n.sandbox.Root, path)
+}
+
+// how many links confine follows before giving up on a path, like the limit
+// of the kernel it stops links that point at each other
+const maxLinks = 40
+
+// resolves the path inside the root, fails if it leads outside of it
+func confine(root string, path string) (string, error) {
+	outside := errors.New("Path '" + path + "' is outside of the sandbox.")
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", outside
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	// cleaning the path as if it was absolute removes any leading ".."
+	joined := filepath.Join(root, filepath.Clean(string(filepath.Separator)+path))
+
+	// symbolic links are followed as far as the path exists, a link to a
+	// missing file is followed to where a created file would end up
+	existing := joined
+	rest := ""
+	for links := 0; ; {
+		if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+			joined = filepath.Join(resolved, rest)
+			break
+		}
+		if info, err := os.Lstat(existing); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(existing)
+			if links++; err != nil || links > maxLinks {
+				return "", outside
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(existing), target)
+			}
+			existing = target
+			continue
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			joined = filepath.Join(existing, rest)
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+
+	relative, err := filepath.Rel(root, joined)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", outside
+	}
+	return joined, nil
+}
+
+// describes a failed file operation without the go specific parts of the error
+func fileError(action string, path Value, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return errors.New("Can't " + action + " '" + stringify(path) + "': " + err.Error() + ".")
+}
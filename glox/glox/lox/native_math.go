@@ -0,0 +1,105 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// returns the argument at the index if it is a number
+func numberArgument(function string, arguments []Value, index int) (float64, error) {
+	n, ok := arguments[index].(float64)
+	if !ok {
+		return 0, fmt.Errorf("Argument %d of %s must be a number.", index+1, function)
+	}
+	return n, nil
+}
+
+// wraps a go function of one number into a native
+func unaryMath(name string, function func(float64) float64) NativeFunc {
+	return func(arguments []Value) (Value, error) {
+		n, err := numberArgument(name, arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		return function(n), nil
+	}
+}
+
+// wraps a go function of two numbers into a native
+func binaryMath(name string, function func(float64, float64) float64) NativeFunc {
+	return func(arguments []Value) (Value, error) {
+		a, err := numberArgument(name, arguments, 0)
+		if err != nil {
+			return nil, err
+		}
+		b, err := numberArgument(name, arguments, 1)
+		if err != nil {
+			return nil, err
+		}
+		return function(a, b), nil
+	}
+}
+
+var (
+	sqrt    = unaryMath("sqrt", math.Sqrt)
+	pow     = binaryMath("pow", math.Pow)
+	floor   = unaryMath("floor", math.Floor)
+	ceil    = unaryMath("ceil", math.Ceil)
+	round   = unaryMath("round", math.Round)
+	abs     = unaryMath("abs", math.Abs)
+	minimum = binaryMath("min", math.Min)
+	maximum = binaryMath("max", math.Max)
+	sin     = unaryMath("sin", math.Sin)
+	cos     = unaryMath("cos", math.Cos)
+	tan     = unaryMath("tan", math.Tan)
+	log     = unaryMath("log", math.Log)
+	exp     = unaryMath("exp", math.Exp)
+)
+
+// random is the random number generator of one engine, seeded from the
+// clock unless the script calls seed to make the numbers reproducible
+type random struct {
+	source *rand.PCG
+	rand   *rand.Rand
+}
+
+func newRandom() *random {
+	source := rand.NewPCG(uint64(time.Now().UnixNano()), 0)
+	return &random{source, rand.New(source)}
+}
+
+// restarts the generator, the same seed always gives the same numbers
+func (r *random) seed(arguments []Value) (Value, error) {
+	seed, err := integerArgument("seed", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	r.source.Seed(uint64(seed), 0)
+	return nil, nil
+}
+
+// returns a number from 0 up to but not including 1
+func (r *random) random(arguments []Value) (Value, error) {
+	return r.rand.Float64(), nil
+}
+
+// returns a whole number from min to max, both included
+func (r *random) randomInt(arguments []Value) (Value, error) {
+	low, err := integerArgument("randomInt", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	high, err := integerArgument("randomInt", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if low > high {
+		return nil, errors.New("Argument 1 of randomInt must not be greater than argument 2.")
+	}
+	return float64(low + r.rand.IntN(high-low+1)), nil
+}
@@ -0,0 +1,229 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// string natives, positions in strings count characters and not bytes
+
+// returns the argument at the index if it is a string
+func stringArgument(function string, arguments []Value, index int) (string, error) {
+	s, ok := arguments[index].(string)
+	if !ok {
+		return "", fmt.Errorf("Argument %d of %s must be a string.", index+1, function)
+	}
+	return s, nil
+}
+
+// returns the argument at the index if it is a whole number
+func integerArgument(function string, arguments []Value, index int) (int, error) {
+	n, ok := arguments[index].(float64)
+	if !ok || n != math.Trunc(n) || math.Abs(n) > math.MaxInt32 {
+		return 0, fmt.Errorf("Argument %d of %s must be an integer.", index+1, function)
+	}
+	return int(n), nil
+}
+
+// returns the characters from start up to but not including end
+func substr(arguments []Value) (Value, error) {
+	s, err := stringArgument("substr", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	start, err := integerArgument("substr", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+	end, err := integerArgument("substr", arguments, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	characters := []rune(s)
+	if start < 0 || end > len(characters) || start > end {
+		return nil, errors.New("Substring bounds out of range.")
+	}
+	return string(characters[start:end]), nil
+}
+
+// returns the position of the first occurrence of the substring, -1 if there is none
+func indexOf(arguments []Value) (Value, error) {
+	s, err := stringArgument("indexOf", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	substring, err := stringArgument("indexOf", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	index := strings.Index(s, substring)
+	if index < 0 {
+		return -1.0, nil
+	}
+	return float64(utf8.RuneCountInString(s[:index])), nil
+}
+
+// splits the string around every separator, an empty separator splits it into characters
+func split(arguments []Value) (Value, error) {
+	s, err := stringArgument("split", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	separator, err := stringArgument("split", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(s, separator)
+	elements := make([]any, len(parts))
+	for i, part := range parts {
+		elements[i] = part
+	}
+	return NewLoxList(elements), nil
+}
+
+// joins the elements of the list with the separator, converting them like print does
+func join(arguments []Value) (Value, error) {
+	list, ok := arguments[0].(*LoxList)
+	if !ok {
+		return nil, errors.New("Argument 1 of join must be a list.")
+	}
+	separator, err := stringArgument("join", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(list.elements))
+	for i, element := range list.elements {
+		parts[i] = stringify(element)
+	}
+	return strings.Join(parts, separator), nil
+}
+
+func upper(arguments []Value) (Value, error) {
+	s, err := stringArgument("upper", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToUpper(s), nil
+}
+
+func lower(arguments []Value) (Value, error) {
+	s, err := stringArgument("lower", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToLower(s), nil
+}
+
+// removes the whitespace around the string
+func trim(arguments []Value) (Value, error) {
+	s, err := stringArgument("trim", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// replaces every occurrence of old with new
+func replace(arguments []Value) (Value, error) {
+	s, err := stringArgument("replace", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	old, err := stringArgument("replace", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+	replacement, err := stringArgument("replace", arguments, 2)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ReplaceAll(s, old, replacement), nil
+}
+
+func startsWith(arguments []Value) (Value, error) {
+	s, err := stringArgument("startsWith", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := stringArgument("startsWith", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+	return strings.HasPrefix(s, prefix), nil
+}
+
+func endsWith(arguments []Value) (Value, error) {
+	s, err := stringArgument("endsWith", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := stringArgument("endsWith", arguments, 1)
+	if err != nil {
+		return nil, err
+	}
+	return strings.HasSuffix(s, suffix), nil
+}
+
+// returns the single character string with the code point
+func chr(arguments []Value) (Value, error) {
+	code, err := integerArgument("chr", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utf8.ValidRune(rune(code)) {
+		return nil, errors.New("Invalid code point " + strconv.Itoa(code) + ".")
+	}
+	return string(rune(code)), nil
+}
+
+// returns the code point of a single character string
+func ord(arguments []Value) (Value, error) {
+	s, err := stringArgument("ord", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if utf8.RuneCountInString(s) != 1 {
+		return nil, errors.New("Argument 1 of ord must be a single character.")
+	}
+	c, _ := utf8.DecodeRuneInString(s)
+	return float64(c), nil
+}
+
+// decimal numbers with an optional sign and exponent, lox source can write
+// neither but numbers written by other programs often have them
+var numberPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// parses the string as a number, returns nil if it is not one
+func toNumber(arguments []Value) (Value, error) {
+	s, err := stringArgument("toNumber", arguments, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s = strings.TrimSpace(s)
+	if !numberPattern.MatchString(s) {
+		return nil, nil
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, nil
+	}
+	return n, nil
+}
+
+// converts any value to the text print would show
+func toString(arguments []Value) (Value, error) {
+	return stringify(arguments[0]), nil
+}
@@ -0,0 +1,871 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type ParseError struct {
+	Message string
+	Token   Token
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Parse error at '%v': %s", e.Token.lexeme, e.Message)
+}
+
+type Parser struct {
+	tokens  []Token
+	current int
+
+	// one entry per function being parsed, set to true once a yield is found
+	// in its body which turns the function into a generator
+	yields []bool
+
+	// number of loops enclosing the statement being parsed, within the current function
+	loopDepth int
+
+	errors CompileErrors
+}
+
+func NewParser(tokens []Token) *Parser {
+	return &Parser{
+		tokens,
+		0,
+		make([]bool, 0, 5),
+		0,
+		nil,
+	}
+}
+
+func (p *Parser) Parse() []Stmt {
+	statements := make([]Stmt, 0, 10)
+	for !p.isAtEnd() {
+		// declaration returns nil when it had to synchronize after an error
+		if stmt := p.declaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+	}
+
+	return statements
+}
+
+func (p *Parser) declaration() Stmt {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*ParseError); ok {
+				// fmt.Println("synchronizing...")
+				p.synchronize()
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	if p.match(CLASS) {
+		return p.classDeclaration()
+	}
+
+	if p.match(VAR) {
+		return p.varDeclaration()
+	}
+
+	// without a name it is an anonymous function in an expression statement
+	if p.check(FUN) && p.checkNext(IDENTIFIER) {
+		p.advance()
+		return p.function("function")
+	}
+
+	return p.statement()
+}
+
+func (p *Parser) classDeclaration() Stmt {
+	name := p.consume(IDENTIFIER, "Expect class name.")
+	p.consume(LEFT_BRACE, "Expect '{' before class body.")
+
+	methods := make([]*Function, 0, 5)
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		methods = append(methods, p.function("method"))
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after class body.")
+
+	return &Class{name, methods}
+}
+
+func (p *Parser) varDeclaration() Stmt {
+	var name Token = p.consume(IDENTIFIER, "Expect variable name.")
+
+	var initializer Expr
+	if p.match(EQUAL) {
+		initializer = p.expression()
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after variable declaration.")
+
+	return &Var{
+		name,
+		initializer,
+	}
+}
+
+func (p *Parser) statement() Stmt {
+	if p.match(PRINT) {
+		return p.printStatement()
+	}
+	if p.match(LEFT_BRACE) {
+		return &Block{p.block()}
+	}
+	if p.match(IF) {
+		return p.ifStatement()
+	}
+	if p.match(WHILE) {
+		return p.whileStatement()
+	}
+	if p.match(FOR) {
+		return p.forStatement()
+	}
+
+	if p.match(RETURN) {
+		return p.returnStatement()
+	}
+
+	if p.match(YIELD) {
+		return p.yieldStatement()
+	}
+
+	if p.match(IMPORT) {
+		return p.importStatement()
+	}
+
+	if p.match(THROW) {
+		return p.throwStatement()
+	}
+
+	if p.match(TRY) {
+		return p.tryStatement()
+	}
+
+	if p.match(BREAK) {
+		return p.breakStatement()
+	}
+
+	if p.match(CONTINUE) {
+		return p.continueStatement()
+	}
+
+	return p.expressionStatement()
+}
+
+func (p *Parser) forStatement() Stmt {
+	keyword := p.previous()
+	p.consume(LEFT_PAREN, "Expect '(' after 'for'.")
+
+	var initializer Stmt
+	if p.match(SEMICOLON) {
+
+	} else if p.match(VAR) {
+		initializer = p.varDeclaration()
+	} else {
+		initializer = p.expressionStatement()
+	}
+
+	var condition Expr
+	if !p.check(SEMICOLON) {
+		condition = p.expression()
+	}
+	p.consume(SEMICOLON, "Expect ';' after loop condition.")
+
+	var increment Expr
+	if !p.check(RIGHT_PAREN) {
+		increment = p.expression()
+	}
+
+	p.consume(RIGHT_PAREN, "Expect ')' after for clauses.")
+
+	body := p.loopBody()
+
+	// the increment is kept apart from the body so that it still runs after a continue
+	if condition == nil {
+		condition = &Literal{true}
+	}
+	body = &While{keyword, condition, body, increment}
+
+	if initializer != nil {
+		body = &Block{[]Stmt{initializer, body}}
+	}
+
+	return body
+}
+
+func (p *Parser) block() []Stmt {
+	statements := []Stmt{}
+
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		if stmt := p.declaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after block")
+	return statements
+}
+
+func (p *Parser) printStatement() Stmt {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(SEMICOLON, "Expect ';' after value")
+	return &Print{keyword, value}
+}
+
+func (p *Parser) returnStatement() Stmt {
+	keyword := p.previous()
+	var value Expr
+
+	if !p.check(SEMICOLON) {
+		value = p.expression()
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after return value.")
+	return &Return{keyword, value}
+}
+
+func (p *Parser) yieldStatement() Stmt {
+	keyword := p.previous()
+	var value Expr
+
+	if !p.check(SEMICOLON) {
+		value = p.expression()
+	}
+
+	// a yield outside of any function is reported by the resolver
+	if len(p.yields) > 0 {
+		p.yields[len(p.yields)-1] = true
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after yield value.")
+	return &Yield{keyword, value}
+}
+
+// without a name the module is bound to the name of its file
+func (p *Parser) importStatement() Stmt {
+	keyword := p.previous()
+
+	if p.match(IDENTIFIER) {
+		name := p.previous()
+		if !p.check(IDENTIFIER) || p.peek().lexeme != "from" {
+			p.error(p.peek(), "Expect 'from' after module name.")
+		}
+		p.advance()
+
+		path := p.consume(STRING, "Expect module path.")
+		p.consume(SEMICOLON, "Expect ';' after import.")
+		return &Import{keyword, path, name}
+	}
+
+	path := p.consume(STRING, "Expect module path.")
+	name := path
+	name.tokenType = IDENTIFIER
+	name.lexeme = moduleName(path.object.(string))
+	if !isIdentifier(name.lexeme) {
+		p.error(path, "Module file name is not an identifier, use 'import name from \"path\"'.")
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after import.")
+	return &Import{keyword, path, name}
+}
+
+func (p *Parser) throwStatement() Stmt {
+	keyword := p.previous()
+	value := p.expression()
+
+	p.consume(SEMICOLON, "Expect ';' after thrown value.")
+	return &Throw{keyword, value}
+}
+
+func (p *Parser) tryStatement() Stmt {
+	keyword := p.previous()
+
+	p.consume(LEFT_BRACE, "Expect '{' after 'try'.")
+	body := p.block()
+
+	stmt := &Try{keyword: keyword, body: body}
+
+	if p.match(CATCH) {
+		p.consume(LEFT_PAREN, "Expect '(' after 'catch'.")
+		stmt.catchName = p.consume(IDENTIFIER, "Expect error variable name.")
+		p.consume(RIGHT_PAREN, "Expect ')' after error variable name.")
+
+		p.consume(LEFT_BRACE, "Expect '{' before catch body.")
+		stmt.catchBody = p.block()
+		stmt.hasCatch = true
+	}
+
+	if p.match(FINALLY) {
+		p.consume(LEFT_BRACE, "Expect '{' after 'finally'.")
+		stmt.finallyBody = p.block()
+	} else if !stmt.hasCatch {
+		p.error(p.peek(), "Expect 'catch' or 'finally' after try block.")
+	}
+
+	return stmt
+}
+
+func (p *Parser) expressionStatement() Stmt {
+	expr := p.expression()
+	p.consume(SEMICOLON, "Expect ';' after expression")
+	return &Expression{expr}
+}
+
+func (p *Parser) function(kind string) *Function {
+	name := p.consume(IDENTIFIER, "Expect "+kind+" name.")
+	p.consume(LEFT_PAREN, "Expect '(' after "+kind+" name.")
+	return p.functionBody(name, kind)
+}
+
+// parses the parameters and the body of a function, the '(' before the
+// parameters has already been consumed
+func (p *Parser) functionBody(name Token, kind string) *Function {
+	parameters := p.parameters()
+	p.consume(LEFT_BRACE, "Expect '{' before "+kind+" body.")
+
+	// loops around the declaration do not reach into the body
+	enclosingLoopDepth := p.loopDepth
+	p.loopDepth = 0
+
+	p.yields = append(p.yields, false)
+	defer func() {
+		p.yields = p.yields[:len(p.yields)-1]
+		p.loopDepth = enclosingLoopDepth
+	}()
+
+	body := p.block()
+	isGenerator := p.yields[len(p.yields)-1]
+	return &Function{name, parameters, body, isGenerator}
+}
+
+func (p *Parser) parameters() []Token {
+	parameters := make([]Token, 0, 5)
+
+	if !p.check(RIGHT_PAREN) {
+		for {
+			if len(parameters) >= 255 {
+				p.error(p.peek(), "Cannot have more than 255 parameters.")
+			}
+			parameters = append(parameters, p.consume(IDENTIFIER, "Expect parameter name."))
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
+	p.consume(RIGHT_PAREN, "Expect ')' after parameters.")
+	return parameters
+}
+
+// fun (a, b) { ... } as an expression
+func (p *Parser) lambda() Expr {
+	keyword := p.previous()
+	p.consume(LEFT_PAREN, "Expect '(' after 'fun'.")
+	return &Lambda{p.functionBody(anonymousName(keyword), "function")}
+}
+
+// (a, b) => expression, the body returns the value of the expression
+func (p *Parser) arrowFunction() Expr {
+	paren := p.advance()
+	parameters := p.parameters()
+	arrow := p.consume(ARROW, "Expect '=>' after parameters.")
+
+	body := []Stmt{&Return{arrow, p.nonCommaExpression()}}
+	return &Lambda{&Function{anonymousName(paren), parameters, body, false}}
+}
+
+// reports whether the tokens ahead are the parameter list of an arrow
+// function rather than a parenthesized expression
+func (p *Parser) isArrowFunction() bool {
+	i := p.current
+	if p.tokens[i].tokenType != LEFT_PAREN {
+		return false
+	}
+
+	i++
+	if p.tokens[i].tokenType == IDENTIFIER {
+		i++
+		for p.tokens[i].tokenType == COMMA && p.tokens[i+1].tokenType == IDENTIFIER {
+			i += 2
+		}
+	}
+
+	return p.tokens[i].tokenType == RIGHT_PAREN && p.tokens[i+1].tokenType == ARROW
+}
+
+// the name token of an anonymous function, its lexeme is empty and it points
+// at the start of the function
+func anonymousName(start Token) Token {
+	start.tokenType = IDENTIFIER
+	start.lexeme = ""
+	return start
+}
+
+func (p *Parser) ifStatement() Stmt {
+	keyword := p.previous()
+
+	p.consume(LEFT_PAREN, "Expect '(' after if")
+	condition := p.expression()
+	p.consume(RIGHT_PAREN, "Expect ')' after if")
+
+	thenStmt := p.statement()
+
+	var elseStmt Stmt
+	if p.match(ELSE) {
+		elseStmt = p.statement()
+	}
+
+	return &If{
+		keyword,
+		condition,
+		thenStmt,
+		elseStmt,
+	}
+}
+
+func (p *Parser) whileStatement() Stmt {
+	keyword := p.previous()
+
+	p.consume(LEFT_PAREN, "Expect '(' after while")
+	condition := p.expression()
+	p.consume(RIGHT_PAREN, "Expect ')' after if")
+
+	body := p.loopBody()
+
+	return &While{keyword, condition, body, nil}
+}
+
+// parses the body of a loop, break and continue are only allowed inside of it
+func (p *Parser) loopBody() Stmt {
+	p.loopDepth++
+	defer func() {
+		p.loopDepth--
+	}()
+
+	return p.statement()
+}
+
+func (p *Parser) breakStatement() Stmt {
+	keyword := p.previous()
+	if p.loopDepth == 0 {
+		p.error(keyword, "Can't use 'break' outside of a loop.")
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after 'break'.")
+	return &Break{keyword}
+}
+
+func (p *Parser) continueStatement() Stmt {
+	keyword := p.previous()
+	if p.loopDepth == 0 {
+		p.error(keyword, "Can't use 'continue' outside of a loop.")
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after 'continue'.")
+	return &Continue{keyword}
+}
+
+func (p *Parser) expression() Expr {
+	return p.comma()
+}
+
+func (p *Parser) comma() Expr {
+	expr := p.nonCommaExpression()
+	if p.peek().tokenType == COMMA {
+		commaExpr := &Comma{
+			[]Expr{expr},
+		}
+		for p.match(COMMA) {
+			commaExpr.exprs = append(commaExpr.exprs, p.nonCommaExpression())
+		}
+		return commaExpr
+	}
+	return expr
+}
+
+func (p *Parser) nonCommaExpression() Expr {
+	return p.assignment()
+}
+
+func (p *Parser) assignment() Expr {
+	expr := p.ternary()
+	if p.match(EQUAL) {
+		equals := p.previous()
+		value := p.assignment()
+
+		if v, ok := expr.(*Variable); ok {
+			name := v.name
+			return &Assign{name, value, globalDepth}
+		}
+
+		if get, ok := expr.(*Get); ok {
+			return &Set{get.object, get.name, value}
+		}
+
+		if index, ok := expr.(*Index); ok {
+			return &SetIndex{index.object, index.bracket, index.index, value}
+		}
+		p.error(equals, "Invalid assignment target")
+	}
+
+	return expr
+}
+
+func (p *Parser) ternary() Expr {
+	expr := p.logicOr()
+
+	if p.match(QUESTION_MARK) {
+		outcome1 := p.expression()
+		p.consume(COLON, "? denotes a ternary operator: expected expr ? expr : expr")
+		outcome2 := p.ternary()
+		return &Ternary{
+			condition: expr,
+			outcome1:  outcome1,
+			outcome2:  outcome2,
+		}
+	}
+	return expr
+}
+
+func (p *Parser) logicOr() Expr {
+	expr := p.logicAnd()
+
+	for p.match(OR) {
+		operator := p.previous()
+		right := p.logicAnd()
+
+		expr = &Logical{expr, operator, right}
+	}
+
+	return expr
+}
+
+func (p *Parser) logicAnd() Expr {
+	expr := p.equality()
+
+	for p.match(AND) {
+		operator := p.previous()
+		right := p.equality()
+
+		expr = &Logical{expr, operator, right}
+	}
+
+	return expr
+}
+
+func (p *Parser) equality() Expr {
+	expr := p.comparison()
+	for p.match(BANG_EQUAL, EQUAL_EQUAL) {
+		operator := p.previous()
+		right := p.comparison()
+		expr = &Binary{expr, operator, right}
+	}
+	return expr
+}
+
+func (p *Parser) comparison() Expr {
+	expr := p.term()
+	for p.match(GREATER, GREATER_EQUAL, LESS, LESS_EQUAL) {
+		operator := p.previous()
+		right := p.term()
+		expr = &Binary{expr, operator, right}
+	}
+	return expr
+}
+
+func (p *Parser) term() Expr {
+	expr := p.factor()
+	for p.match(MINUS, PLUS) {
+		operator := p.previous()
+		right := p.factor()
+		expr = &Binary{expr, operator, right}
+	}
+	return expr
+}
+
+func (p *Parser) factor() Expr {
+	expr := p.unary()
+	for p.match(SLASH, STAR, PERCENT) {
+		operator := p.previous()
+		right := p.unary()
+		expr = &Binary{expr, operator, right}
+	}
+	return expr
+}
+
+func (p *Parser) unary() Expr {
+	if p.match(BANG, MINUS) {
+		operator := p.previous()
+		right := p.unary()
+		return &Unary{operator, right}
+	}
+	return p.call()
+}
+
+func (p *Parser) call() Expr {
+	expr := p.primary()
+
+	for {
+		if p.match(LEFT_PAREN) {
+			expr = p.finishCall(expr)
+		} else if p.match(DOT) {
+			name := p.consume(IDENTIFIER, "Expect property name after '.'.")
+			expr = &Get{expr, name}
+		} else if p.match(LEFT_BRACKET) {
+			bracket := p.previous()
+			index := p.expression()
+			p.consume(RIGHT_BRACKET, "Expect ']' after index.")
+			expr = &Index{expr, bracket, index}
+		} else {
+			break
+		}
+	}
+
+	return expr
+}
+
+func (p *Parser) finishCall(callee Expr) Expr {
+	arguments := make([]Expr, 0, 5)
+
+	if !p.check(RIGHT_PAREN) {
+		for {
+			if len(arguments) >= 255 {
+				p.error(p.peek(), "Cannot have more than 255 arguments")
+			}
+
+			arguments = append(arguments, p.nonCommaExpression())
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
+	paren := p.consume(RIGHT_PAREN, "Expect ')' after arguments.")
+
+	return &Call{callee, paren, arguments}
+
+}
+
+func (p *Parser) primary() Expr {
+	if p.match(FALSE) {
+		return &Literal{false}
+	} else if p.match(TRUE) {
+		return &Literal{true}
+	} else if p.match(NIL) {
+		return &Literal{nil}
+	}
+
+	if p.match(NUMBER, STRING) {
+		return &Literal{p.previous().object}
+	}
+
+	if p.match(INTERPOLATION) {
+		return p.interpolation()
+	}
+
+	if p.match(THIS) {
+		return &This{p.previous(), globalDepth}
+	}
+
+	if p.match(IDENTIFIER) {
+		return &Variable{p.previous(), globalDepth}
+	}
+
+	if p.match(FUN) {
+		return p.lambda()
+	}
+
+	if p.isArrowFunction() {
+		return p.arrowFunction()
+	}
+
+	if p.match(LEFT_PAREN) {
+		expr := p.expression()
+		p.consume(RIGHT_PAREN, "Expect ')' after expression")
+		return &Grouping{expr}
+	}
+
+	if p.match(LEFT_BRACKET) {
+		return p.list()
+	}
+
+	if p.match(LEFT_BRACE) {
+		return p.mapLiteral()
+	}
+
+	p.error(p.peek(), "Expect expression.")
+	panic("") // unreachable
+}
+
+// parses an interpolated string after its first part, the parts are the
+// strings between the expressions and the expressions themselves
+func (p *Parser) interpolation() Expr {
+	parts := make([]Expr, 0, 5)
+
+	for {
+		parts = append(parts, &Literal{p.previous().object})
+
+		// the rest of the string follows right away when the ${} is empty
+		if continuesString(p.peek()) {
+			p.error(interpolationStart(p.previous()), "Expect expression.")
+		}
+		parts = append(parts, p.expression())
+		if !p.match(INTERPOLATION) {
+			break
+		}
+	}
+
+	p.consume(STRING, "Expect '}' after interpolated expression.")
+	parts = append(parts, &Literal{p.previous().object})
+	return &Interpolation{parts}
+}
+
+// the "${" that ends the part of a string before an interpolated expression
+func interpolationStart(part Token) Token {
+	before := part.lexeme[:len(part.lexeme)-len("${")]
+
+	start := part
+	start.lexeme = "${"
+	start.offset += len(before)
+	if newline := strings.LastIndexByte(before, '\n'); newline >= 0 {
+		start.line += strings.Count(before, "\n")
+		start.column = utf8.RuneCountInString(before[newline+1:]) + 1
+	} else {
+		start.column += utf8.RuneCountInString(before)
+	}
+	return start
+}
+
+// parses the elements of a list literal after its opening bracket
+func (p *Parser) list() Expr {
+	bracket := p.previous()
+	elements := make([]Expr, 0, 5)
+
+	if !p.check(RIGHT_BRACKET) {
+		for {
+			elements = append(elements, p.nonCommaExpression())
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
+	p.consume(RIGHT_BRACKET, "Expect ']' after list elements.")
+	return &List{bracket, elements}
+}
+
+// parses the entries of a map literal after its opening brace, a brace
+// starting a statement is a block instead
+func (p *Parser) mapLiteral() Expr {
+	brace := p.previous()
+	keys := make([]Expr, 0, 5)
+	values := make([]Expr, 0, 5)
+
+	if !p.check(RIGHT_BRACE) {
+		for {
+			keys = append(keys, p.nonCommaExpression())
+			p.consume(COLON, "Expect ':' after map key.")
+			values = append(values, p.nonCommaExpression())
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after map entries.")
+	return &Map{brace, keys, values}
+}
+
+func (p *Parser) consume(tokenType TokenType, message string) Token {
+	if p.check(tokenType) {
+		return p.advance()
+	}
+
+	p.error(p.peek(), message)
+	return Token{}
+}
+
+func (p *Parser) error(token Token, message string) {
+	var err = &ParseError{
+		message,
+		token,
+	}
+
+	p.errors = append(p.errors, newCompileError(token, message))
+	panic(err)
+}
+
+func (p *Parser) synchronize() {
+	p.advance()
+	for !p.isAtEnd() {
+		if p.previous().tokenType == SEMICOLON {
+			return
+		}
+
+		switch p.peek().tokenType {
+		case CLASS:
+		case FOR:
+		case FUN:
+		case IF:
+		case PRINT:
+		case RETURN:
+		case VAR:
+		case WHILE:
+			return
+		}
+
+		p.advance()
+	}
+}
+
+func (p *Parser) match(tokenTypes ...TokenType) bool {
+	for _, tokenT := range tokenTypes {
+		if p.check(tokenT) {
+			p.advance()
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) checkNext(tokenType TokenType) bool {
+	if p.isAtEnd() || p.current+1 >= len(p.tokens) {
+		return false
+	}
+	return p.tokens[p.current+1].tokenType == tokenType
+}
+
+func (p *Parser) check(tokenType TokenType) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	// fmt.Println(tokenType)
+	return p.peek().tokenType == tokenType
+}
+
+func (p *Parser) advance() Token {
+	if !p.isAtEnd() {
+		p.current += 1
+	}
+	return p.previous()
+}
+
+func (p *Parser) previous() Token {
+	return p.tokens[p.current-1]
+}
+
+func (p *Parser) isAtEnd() bool {
+	return p.peek().tokenType == EOF
+}
+
+func (p *Parser) peek() Token {
+	return p.tokens[p.current]
+}
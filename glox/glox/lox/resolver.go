@@ -0,0 +1,433 @@
+package lox
+
+type functionType int
+
+const (
+	functionNone functionType = iota
+	functionFunction
+	functionMethod
+	functionInitializer
+)
+
+type classType int
+
+const (
+	classNone classType = iota
+	classClass
+)
+
+// the depth of a variable no local scope declares, it is a global
+const globalDepth = -1
+
+// Resolver walks the parsed statements once before they are interpreted,
+// it records in the Variable, Assign and This nodes how many scopes away the
+// local variable they use lives and reports the errors that can be found
+// without running the program
+type Resolver struct {
+	// one map per local scope, the value tells if the variable is already
+	// defined (true) or only declared (false)
+	scopes []map[string]bool
+
+	currentFunction  functionType
+	currentGenerator bool
+	currentClass     classType
+
+	errors CompileErrors
+
+	// where names are declared and used, only kept for Analyze
+	symbols *symbolTable
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		scopes:          make([]map[string]bool, 0, 10),
+		currentFunction: functionNone,
+		currentClass:    classNone,
+	}
+}
+
+func (r *Resolver) Resolve(statements []Stmt) {
+	for _, stmt := range statements {
+		r.resolveStmt(stmt)
+	}
+}
+
+func (r *Resolver) resolveStmt(stmt Stmt) {
+	stmt.Accept(r)
+}
+
+func (r *Resolver) resolveExpr(expr Expr) {
+	expr.Accept(r)
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool, 10))
+	if r.symbols != nil {
+		r.symbols.beginScope()
+	}
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+	if r.symbols != nil {
+		r.symbols.endScope()
+	}
+}
+
+// notes the declaration of a name for Analyze
+func (r *Resolver) record(name Token, kind SymbolKind, signature string) {
+	if r.symbols != nil {
+		r.symbols.declare(name.lexeme, tokenLocation(name), kind, signature)
+	}
+}
+
+func (r *Resolver) error(token Token, message string) {
+	r.errors = append(r.errors, newCompileError(token, message))
+}
+
+// adds the variable to the innermost scope, globals are not tracked
+func (r *Resolver) declare(name Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	scope := r.scopes[len(r.scopes)-1]
+	if _, ok := scope[name.lexeme]; ok {
+		r.error(name, "Already a variable with this name in this scope.")
+	}
+
+	scope[name.lexeme] = false
+}
+
+// marks the variable as ready to use
+func (r *Resolver) define(name Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	r.scopes[len(r.scopes)-1][name.lexeme] = true
+}
+
+// finds the scope the variable lives in and records its distance in depth,
+// variables not found in any scope are assumed to be globals
+func (r *Resolver) resolveLocal(depth *int, name Token) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name.lexeme]; ok {
+			*depth = len(r.scopes) - 1 - i
+			if r.symbols != nil {
+				r.symbols.use(name, i)
+			}
+			return
+		}
+	}
+
+	if r.symbols != nil {
+		r.symbols.use(name, -1)
+	}
+}
+
+func (r *Resolver) resolveFunction(function *Function, kind functionType) {
+	enclosingFunction := r.currentFunction
+	enclosingGenerator := r.currentGenerator
+	r.currentFunction = kind
+	r.currentGenerator = function.isGenerator
+
+	r.beginScope()
+	for _, param := range function.params {
+		r.declare(param)
+		r.define(param)
+		r.record(param, SymbolParameter, param.lexeme)
+	}
+	r.Resolve(function.body)
+	r.endScope()
+
+	r.currentFunction = enclosingFunction
+	r.currentGenerator = enclosingGenerator
+}
+
+func (r *Resolver) VisitBlockStmt(stmt *Block) any {
+	r.beginScope()
+	r.Resolve(stmt.statements)
+	r.endScope()
+	return nil
+}
+
+func (r *Resolver) VisitClassStmt(stmt *Class) any {
+	enclosingClass := r.currentClass
+	r.currentClass = classClass
+
+	r.declare(stmt.name)
+	r.define(stmt.name)
+	r.record(stmt.name, SymbolClass, classSignature(stmt))
+
+	// methods are closures over a scope that holds "this"
+	r.beginScope()
+	r.scopes[len(r.scopes)-1]["this"] = true
+
+	for _, method := range stmt.methods {
+		kind := functionMethod
+		if method.name.lexeme == "init" {
+			kind = functionInitializer
+		}
+		r.resolveFunction(method, kind)
+	}
+
+	r.endScope()
+
+	r.currentClass = enclosingClass
+	return nil
+}
+
+func (r *Resolver) VisitVarStmt(stmt *Var) any {
+	r.declare(stmt.name)
+	r.record(stmt.name, SymbolVariable, "var "+stmt.name.lexeme)
+	if stmt.initializer != nil {
+		r.resolveExpr(stmt.initializer)
+	}
+	r.define(stmt.name)
+	return nil
+}
+
+func (r *Resolver) VisitFunctionStmt(stmt *Function) any {
+	// defined before the body so that the function can call itself
+	r.declare(stmt.name)
+	r.define(stmt.name)
+	r.record(stmt.name, SymbolFunction, functionSignature("fun ", stmt))
+
+	r.resolveFunction(stmt, functionFunction)
+	return nil
+}
+
+func (r *Resolver) VisitExpressionStmt(stmt *Expression) any {
+	r.resolveExpr(stmt.expression)
+	return nil
+}
+
+func (r *Resolver) VisitIfStmt(stmt *If) any {
+	r.resolveExpr(stmt.condition)
+	r.resolveStmt(stmt.thenBranch)
+	if stmt.elseBranch != nil {
+		r.resolveStmt(stmt.elseBranch)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitPrintStmt(stmt *Print) any {
+	r.resolveExpr(stmt.expression)
+	return nil
+}
+
+func (r *Resolver) VisitReturnStmt(stmt *Return) any {
+	if r.currentFunction == functionNone {
+		r.error(stmt.keyword, "Can't return from top-level code.")
+	}
+
+	if stmt.value != nil {
+		if r.currentFunction == functionInitializer {
+			r.error(stmt.keyword, "Can't return a value from an initializer.")
+		}
+		if r.currentGenerator {
+			r.error(stmt.keyword, "Can't return a value from a generator.")
+		}
+		r.resolveExpr(stmt.value)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitImportStmt(stmt *Import) any {
+	r.declare(stmt.name)
+	r.define(stmt.name)
+	if r.symbols != nil {
+		// without a name the module is declared by its path
+		at := tokenLocation(stmt.name)
+		if stmt.name.column == stmt.path.column {
+			at = tokenLocation(stmt.path)
+		}
+		signature := "import " + stmt.name.lexeme + " from " + stmt.path.lexeme
+		r.symbols.declare(stmt.name.lexeme, at, SymbolModule, signature)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitThrowStmt(stmt *Throw) any {
+	r.resolveExpr(stmt.value)
+	return nil
+}
+
+func (r *Resolver) VisitTryStmt(stmt *Try) any {
+	r.beginScope()
+	r.Resolve(stmt.body)
+	r.endScope()
+
+	// the error variable is only in scope of the catch body
+	if stmt.hasCatch {
+		r.beginScope()
+		r.declare(stmt.catchName)
+		r.define(stmt.catchName)
+		r.record(stmt.catchName, SymbolVariable, "catch ("+stmt.catchName.lexeme+")")
+		r.Resolve(stmt.catchBody)
+		r.endScope()
+	}
+
+	r.beginScope()
+	r.Resolve(stmt.finallyBody)
+	r.endScope()
+	return nil
+}
+
+func (r *Resolver) VisitWhileStmt(stmt *While) any {
+	r.resolveExpr(stmt.condition)
+	r.resolveStmt(stmt.body)
+	if stmt.increment != nil {
+		r.resolveExpr(stmt.increment)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitBreakStmt(stmt *Break) any {
+	return nil
+}
+
+func (r *Resolver) VisitContinueStmt(stmt *Continue) any {
+	return nil
+}
+
+func (r *Resolver) VisitYieldStmt(stmt *Yield) any {
+	switch r.currentFunction {
+	case functionNone:
+		r.error(stmt.keyword, "Can't yield from top-level code.")
+	case functionInitializer:
+		r.error(stmt.keyword, "Can't yield from an initializer.")
+	}
+
+	if stmt.value != nil {
+		r.resolveExpr(stmt.value)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitLambdaExpr(expr *Lambda) any {
+	r.resolveFunction(expr.function, functionFunction)
+	return nil
+}
+
+func (r *Resolver) VisitVariableExpr(expr *Variable) any {
+	if len(r.scopes) > 0 {
+		if defined, ok := r.scopes[len(r.scopes)-1][expr.name.lexeme]; ok && !defined {
+			r.error(expr.name, "Can't read local variable in its own initializer.")
+		}
+	}
+
+	r.resolveLocal(&expr.depth, expr.name)
+	return nil
+}
+
+func (r *Resolver) VisitAssignExpr(expr *Assign) any {
+	r.resolveExpr(expr.value)
+	r.resolveLocal(&expr.depth, expr.name)
+	return nil
+}
+
+func (r *Resolver) VisitBinaryExpr(expr *Binary) any {
+	r.resolveExpr(expr.left)
+	r.resolveExpr(expr.right)
+	return nil
+}
+
+func (r *Resolver) VisitCallExpr(expr *Call) any {
+	r.resolveExpr(expr.callee)
+	for _, argument := range expr.arguments {
+		r.resolveExpr(argument)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitGetExpr(expr *Get) any {
+	r.resolveExpr(expr.object)
+	return nil
+}
+
+func (r *Resolver) VisitSetExpr(expr *Set) any {
+	r.resolveExpr(expr.value)
+	r.resolveExpr(expr.object)
+	return nil
+}
+
+func (r *Resolver) VisitInterpolationExpr(expr *Interpolation) any {
+	for _, part := range expr.parts {
+		r.resolveExpr(part)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitListExpr(expr *List) any {
+	for _, element := range expr.elements {
+		r.resolveExpr(element)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitMapExpr(expr *Map) any {
+	for i := range expr.keys {
+		r.resolveExpr(expr.keys[i])
+		r.resolveExpr(expr.values[i])
+	}
+	return nil
+}
+
+func (r *Resolver) VisitIndexExpr(expr *Index) any {
+	r.resolveExpr(expr.object)
+	r.resolveExpr(expr.index)
+	return nil
+}
+
+func (r *Resolver) VisitSetIndexExpr(expr *SetIndex) any {
+	r.resolveExpr(expr.value)
+	r.resolveExpr(expr.object)
+	r.resolveExpr(expr.index)
+	return nil
+}
+
+func (r *Resolver) VisitThisExpr(expr *This) any {
+	if r.currentClass == classNone {
+		r.error(expr.keyword, "Can't use 'this' outside of a class.")
+		return nil
+	}
+
+	r.resolveLocal(&expr.depth, expr.keyword)
+	return nil
+}
+
+func (r *Resolver) VisitGroupingExpr(expr *Grouping) any {
+	r.resolveExpr(expr.expression)
+	return nil
+}
+
+func (r *Resolver) VisitLiteralExpr(expr *Literal) any {
+	return nil
+}
+
+func (r *Resolver) VisitLogicalExpr(expr *Logical) any {
+	r.resolveExpr(expr.left)
+	r.resolveExpr(expr.right)
+	return nil
+}
+
+func (r *Resolver) VisitUnaryExpr(expr *Unary) any {
+	r.resolveExpr(expr.right)
+	return nil
+}
+
+func (r *Resolver) VisitTernaryExpr(expr *Ternary) any {
+	r.resolveExpr(expr.condition)
+	r.resolveExpr(expr.outcome1)
+	r.resolveExpr(expr.outcome2)
+	return nil
+}
+
+func (r *Resolver) VisitCommaExpr(expr *Comma) any {
+	for _, e := range expr.exprs {
+		r.resolveExpr(e)
+	}
+	return nil
+}
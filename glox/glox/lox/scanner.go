@@ -0,0 +1,477 @@
+package lox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scanner turns the source into tokens, the source is read as UTF-8 and
+// columns count characters, not bytes
+//
+// strings support these escape sequences:
+//
+//	\n  line feed
+//	\t  tab
+//	\r  carriage return
+//	\0  null character
+//	\\  backslash
+//	\"  double quote
+//	\u{1F600}  the unicode character with the given 1 to 6 hex digit code point
+//	\$  dollar sign, needed to write "${" without starting an interpolation
+//
+// an expression inside ${ } is interpolated into the string, the string is
+// split into an INTERPOLATION token for every part followed by an expression,
+// the tokens of the expression, and a final STRING token
+type Scanner struct {
+	source string
+	tokens []Token
+
+	// path of the file the source was read from, given to every token
+	file string
+
+	start   int
+	current int
+	line    int
+
+	// offset of the first character of the current line
+	lineStart int
+
+	// position of the token being scanned
+	startLine   int
+	startColumn int
+
+	// one entry per string whose interpolated expression is being scanned,
+	// counts the braces opened in the expression
+	interpolations []int
+
+	// the comments in the source, the parser never sees them
+	comments []Token
+
+	errors CompileErrors
+}
+
+func NewScanner(source string) *Scanner {
+	s := Scanner{
+		source: source,
+		line:   1,
+	}
+
+	s.tokens = make([]Token, 0, 10)
+	return &s
+}
+
+func (s *Scanner) scanTokens() []Token {
+	for !s.isAtEnd() {
+		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.column(s.current)
+		s.scanToken()
+	}
+
+	s.tokens = append(s.tokens, Token{
+		tokenType: EOF,
+		lexeme:    "",
+		object:    nil,
+		line:      s.line,
+		column:    s.column(s.current),
+		offset:    s.current,
+		file:      s.file,
+	})
+
+	return s.tokens
+}
+
+// the column of the offset on the current line
+func (s *Scanner) column(offset int) int {
+	return utf8.RuneCountInString(s.source[s.lineStart:offset]) + 1
+}
+
+// reports an error at the token being scanned
+func (s *Scanner) error(message string) {
+	s.errors = append(s.errors, &CompileError{
+		Line:    s.startLine,
+		Column:  s.startColumn,
+		Length:  utf8.RuneCountInString(s.source[s.start:s.current]),
+		File:    s.file,
+		Message: message,
+	})
+}
+
+// reports an error at the characters from the offset up to the current one,
+// all on the current line
+func (s *Scanner) errorAt(offset int, message string) {
+	s.errors = append(s.errors, &CompileError{
+		Line:    s.line,
+		Column:  s.column(offset),
+		Length:  utf8.RuneCountInString(s.source[offset:s.current]),
+		File:    s.file,
+		Message: message,
+	})
+}
+
+// called after consuming a line break
+func (s *Scanner) newline() {
+	s.line++
+	s.lineStart = s.current
+}
+
+func (s *Scanner) scanToken() {
+	c := s.advance()
+	switch c {
+	case '(':
+		s.addToken(LEFT_PAREN, nil)
+	case ')':
+		s.addToken(RIGHT_PAREN, nil)
+	case '{':
+		if len(s.interpolations) > 0 {
+			s.interpolations[len(s.interpolations)-1]++
+		}
+		s.addToken(LEFT_BRACE, nil)
+	case '}':
+		if len(s.interpolations) > 0 {
+			top := len(s.interpolations) - 1
+			if s.interpolations[top] == 0 {
+				// the interpolated expression ends, the rest of the string follows
+				s.interpolations = s.interpolations[:top]
+				s.string()
+				return
+			}
+			s.interpolations[top]--
+		}
+		s.addToken(RIGHT_BRACE, nil)
+	case '[':
+		s.addToken(LEFT_BRACKET, nil)
+	case ']':
+		s.addToken(RIGHT_BRACKET, nil)
+	case ',':
+		s.addToken(COMMA, nil)
+	case '.':
+		s.addToken(DOT, nil)
+	case '-':
+		s.addToken(MINUS, nil)
+	case '+':
+		s.addToken(PLUS, nil)
+	case ';':
+		s.addToken(SEMICOLON, nil)
+	case '*':
+		s.addToken(STAR, nil)
+	case '%':
+		s.addToken(PERCENT, nil)
+	case ':': // ternary
+		s.addToken(COLON, nil)
+	case '?': // ternary
+		s.addToken(QUESTION_MARK, nil)
+	case '!':
+		isNE := s.match('=')
+		if isNE {
+			s.addToken(BANG_EQUAL, nil)
+		} else {
+			s.addToken(BANG, nil)
+		}
+	case '=':
+		isEQEQ := s.match('=')
+		if isEQEQ {
+			s.addToken(EQUAL_EQUAL, nil)
+		} else if s.match('>') {
+			s.addToken(ARROW, nil)
+		} else {
+			s.addToken(EQUAL, nil)
+		}
+	case '<':
+		isLE := s.match('=')
+		if isLE {
+			s.addToken(LESS_EQUAL, nil)
+		} else {
+			s.addToken(LESS, nil)
+		}
+	case '>':
+		isGE := s.match('=')
+		if isGE {
+			s.addToken(GREATER_EQUAL, nil)
+		} else {
+			s.addToken(GREATER, nil)
+		}
+	case '/':
+		if s.match('/') {
+			// the line break is left for the next token
+			for s.peek() != '\n' && !s.isAtEnd() {
+				s.advance()
+			}
+			s.addComment()
+		} else if s.match('*') {
+			for {
+				next := s.peek()
+				nextNext := s.peekNext()
+
+				if next == '*' && nextNext == '/' {
+					s.current += 2
+					s.addComment()
+					break
+				}
+
+				if s.isAtEnd() {
+					s.error("Nonterminated multiline comment")
+					break
+				}
+
+				s.advance()
+
+				if next == '\n' {
+					s.newline()
+				}
+			}
+		} else {
+			s.addToken(SLASH, nil)
+		}
+	case ' ':
+	case '\r':
+	case '\t':
+	case '\n':
+		s.newline()
+	case '"':
+		s.string()
+	default:
+		if isDigit(c) {
+			s.number()
+		} else if isAlpha(c) {
+			s.identifier()
+		} else {
+			s.error("Unexpected character")
+		}
+	}
+}
+
+func (s *Scanner) addToken(tokenType TokenType, object Object) {
+	text := s.source[s.start:s.current]
+	s.tokens = append(s.tokens, Token{
+		tokenType: tokenType,
+		lexeme:    text,
+		object:    object,
+		line:      s.startLine,
+		column:    s.startColumn,
+		offset:    s.start,
+		file:      s.file,
+	})
+}
+
+func (s *Scanner) addComment() {
+	s.comments = append(s.comments, Token{
+		tokenType: COMMENT,
+		lexeme:    s.source[s.start:s.current],
+		line:      s.startLine,
+		column:    s.startColumn,
+		offset:    s.start,
+		file:      s.file,
+	})
+}
+
+func (s *Scanner) isAtEnd() bool {
+	return s.current >= len(s.source)
+}
+
+// move the current counter, return next character, invalid UTF-8 is
+// returned as utf8.RuneError one byte at a time
+func (s *Scanner) advance() rune {
+	c, size := utf8.DecodeRuneInString(s.source[s.current:])
+	s.current += size
+	return c
+}
+
+// peek at the next character, 0 if end reached
+func (s *Scanner) peek() rune {
+	if s.isAtEnd() {
+		return 0
+	}
+	c, _ := utf8.DecodeRuneInString(s.source[s.current:])
+	return c
+}
+
+// peek at the next next character, 0 if end reached
+func (s *Scanner) peekNext() rune {
+	if s.isAtEnd() {
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(s.source[s.current:])
+	if s.current+size >= len(s.source) {
+		return 0
+	}
+	c, _ := utf8.DecodeRuneInString(s.source[s.current+size:])
+	return c
+}
+
+// if the next character is the expected one -> consume it and return true, otherwise return false
+func (s *Scanner) match(expected rune) bool {
+	if s.peek() != expected || s.isAtEnd() {
+		return false
+	}
+
+	s.advance()
+	return true
+}
+
+// process a string, decoding its escape sequences, up to the closing quote
+// or up to the start of an interpolated expression
+func (s *Scanner) string() {
+	var value strings.Builder
+
+	for s.peek() != '"' && !s.isAtEnd() {
+		offset := s.current
+		c := s.advance()
+
+		switch {
+		case c == '$' && s.peek() == '{':
+			s.advance()
+			s.addToken(INTERPOLATION, value.String())
+			s.interpolations = append(s.interpolations, 0)
+			return
+		case c == '\n':
+			s.newline()
+			value.WriteRune(c)
+		case c == '\\':
+			s.escape(&value)
+		case c == utf8.RuneError && s.current-offset == 1:
+			s.errorAt(offset, "Invalid UTF-8 in string.")
+		default:
+			value.WriteRune(c)
+		}
+	}
+
+	if s.isAtEnd() {
+		s.error("Unterminated string")
+		return
+	}
+
+	s.advance()
+
+	s.addToken(STRING, value.String())
+}
+
+// decodes the escape sequence after a backslash into the value
+func (s *Scanner) escape(value *strings.Builder) {
+	offset := s.current - 1
+	if s.isAtEnd() {
+		return
+	}
+
+	c := s.advance()
+	switch c {
+	case 'n':
+		value.WriteRune('\n')
+	case 't':
+		value.WriteRune('\t')
+	case 'r':
+		value.WriteRune('\r')
+	case '0':
+		value.WriteRune(0)
+	case '\\', '"', '$':
+		value.WriteRune(c)
+	case 'u':
+		s.unicodeEscape(value, offset)
+	case '\n':
+		s.newline()
+		s.errorAt(offset, "Invalid escape sequence.")
+	default:
+		s.errorAt(offset, "Invalid escape sequence '\\"+string(c)+"'.")
+	}
+}
+
+// decodes the \u{...} escape sequence starting at the offset, the backslash
+// and the u are already consumed
+func (s *Scanner) unicodeEscape(value *strings.Builder, offset int) {
+	if !s.match('{') {
+		s.errorAt(offset, "Expect '{' after '\\u'.")
+		return
+	}
+
+	digits := s.current
+	for isHexDigit(s.peek()) {
+		s.advance()
+	}
+	hex := s.source[digits:s.current]
+
+	if !s.match('}') {
+		s.errorAt(offset, "Expect '}' after unicode code point.")
+		return
+	}
+
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) == 0 || len(hex) > 6 || err != nil || !utf8.ValidRune(rune(code)) {
+		s.errorAt(offset, "Invalid unicode code point '"+hex+"'.")
+		return
+	}
+
+	value.WriteRune(rune(code))
+}
+
+// process a number
+func (s *Scanner) number() {
+
+	for isDigit(s.peek()) {
+		s.advance()
+	}
+
+	if s.peek() == '.' && isDigit(s.peekNext()) {
+		s.advance()
+
+		for isDigit(s.peek()) {
+			s.advance()
+		}
+	}
+
+	numberAsString := s.source[s.start:s.current]
+	n, err := strconv.ParseFloat(numberAsString, 64)
+	if err != nil {
+		fmt.Println()
+		panic(err)
+	}
+
+	s.addToken(NUMBER, n)
+
+}
+
+// process an identifier
+func (s *Scanner) identifier() {
+	for isAlphaNumeric(s.peek()) {
+		s.advance()
+	}
+
+	text := s.source[s.start:s.current]
+	tokenType := keyword(text)
+
+	s.addToken(tokenType, nil)
+}
+
+func isDigit(c rune) bool {
+	if c >= '0' && c <= '9' {
+		return true
+	}
+	return false
+}
+
+func isAlpha(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || (c > unicode.MaxASCII && unicode.IsLetter(c))
+}
+
+func isHexDigit(c rune) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isAlphaNumeric(c rune) bool {
+	return isAlpha(c) || isDigit(c)
+}
+
+// reports whether the text would be scanned as a single identifier
+func isIdentifier(text string) bool {
+	if text == "" || keyword(text) != IDENTIFIER {
+		return false
+	}
+	for i, c := range text {
+		if !isAlpha(c) && (i == 0 || !isDigit(c)) {
+			return false
+		}
+	}
+	return true
+}
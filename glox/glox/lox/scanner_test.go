@@ -0,0 +1,44 @@
+package lox
+
+import (
+	"testing"
+)
+
+func TestMultilineComments(t *testing.T) {
+	program := "x+=2/* fsdfsdf" +
+		"fsdfl;ksdf sdflsdkfj <3 !=" +
+		"fsdfsdf */-3"
+
+	NewInterpreter().Run(program)
+}
+
+func TestStringEscapes(t *testing.T) {
+	scanner := NewScanner(`"a\tb\\\"\u{e9}\0"`)
+	tokens := scanner.scanTokens()
+
+	if len(scanner.errors) != 0 {
+		t.Fatal(scanner.errors)
+	}
+	if tokens[0].object != "a\tb\\\"é\x00" {
+		t.Errorf("unexpected string value %q", tokens[0].object)
+	}
+}
+
+func TestInvalidEscapes(t *testing.T) {
+	scanner := NewScanner("\"é\\x\" \"\\u{d800}\" \"\\u{}\"")
+	scanner.scanTokens()
+
+	expected := []string{
+		"[line 1:3] Error: Invalid escape sequence '\\x'.",
+		"[line 1:8] Error: Invalid unicode code point 'd800'.",
+		"[line 1:19] Error: Invalid unicode code point ''.",
+	}
+	if len(scanner.errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), scanner.errors)
+	}
+	for i, err := range scanner.errors {
+		if err.Error() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], err)
+		}
+	}
+}
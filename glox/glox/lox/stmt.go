@@ -0,0 +1,164 @@
+package lox
+
+type stmtVisitor interface {
+	VisitBlockStmt(stmt *Block) any
+	VisitBreakStmt(stmt *Break) any
+	VisitClassStmt(stmt *Class) any
+	VisitContinueStmt(stmt *Continue) any
+	VisitExpressionStmt(stmt *Expression) any
+	VisitFunctionStmt(stmt *Function) any
+	VisitPrintStmt(stmt *Print) any
+	VisitReturnStmt(stmt *Return) any
+	VisitThrowStmt(stmt *Throw) any
+	VisitTryStmt(stmt *Try) any
+	VisitVarStmt(stmt *Var) any
+	VisitIfStmt(stmt *If) any
+	VisitImportStmt(stmt *Import) any
+	VisitWhileStmt(stmt *While) any
+	VisitYieldStmt(stmt *Yield) any
+}
+
+type Stmt interface {
+	Accept(visitor stmtVisitor) any
+}
+type Block struct {
+	statements []Stmt
+}
+
+func (b *Block) Accept(visitor stmtVisitor) any {
+	return visitor.VisitBlockStmt(b)
+}
+
+type Break struct {
+	keyword Token
+}
+
+func (b *Break) Accept(visitor stmtVisitor) any {
+	return visitor.VisitBreakStmt(b)
+}
+
+type Class struct {
+	name    Token
+	methods []*Function
+}
+
+func (c *Class) Accept(visitor stmtVisitor) any {
+	return visitor.VisitClassStmt(c)
+}
+
+type Continue struct {
+	keyword Token
+}
+
+func (c *Continue) Accept(visitor stmtVisitor) any {
+	return visitor.VisitContinueStmt(c)
+}
+
+type Expression struct {
+	expression Expr
+}
+
+func (e *Expression) Accept(visitor stmtVisitor) any {
+	return visitor.VisitExpressionStmt(e)
+}
+
+type Function struct {
+	name        Token
+	params      []Token
+	body        []Stmt
+	isGenerator bool
+}
+
+func (f *Function) Accept(visitor stmtVisitor) any {
+	return visitor.VisitFunctionStmt(f)
+}
+
+type Print struct {
+	keyword    Token
+	expression Expr
+}
+
+func (p *Print) Accept(visitor stmtVisitor) any {
+	return visitor.VisitPrintStmt(p)
+}
+
+type Return struct {
+	keyword Token
+	value   Expr
+}
+
+func (r *Return) Accept(visitor stmtVisitor) any {
+	return visitor.VisitReturnStmt(r)
+}
+
+type Throw struct {
+	keyword Token
+	value   Expr
+}
+
+func (t *Throw) Accept(visitor stmtVisitor) any {
+	return visitor.VisitThrowStmt(t)
+}
+
+type Try struct {
+	keyword     Token
+	body        []Stmt
+	catchName   Token
+	catchBody   []Stmt
+	finallyBody []Stmt
+	hasCatch    bool
+}
+
+func (t *Try) Accept(visitor stmtVisitor) any {
+	return visitor.VisitTryStmt(t)
+}
+
+type Var struct {
+	name        Token
+	initializer Expr
+}
+
+func (v *Var) Accept(visitor stmtVisitor) any {
+	return visitor.VisitVarStmt(v)
+}
+
+type If struct {
+	keyword    Token
+	condition  Expr
+	thenBranch Stmt
+	elseBranch Stmt
+}
+
+func (i *If) Accept(visitor stmtVisitor) any {
+	return visitor.VisitIfStmt(i)
+}
+
+type Import struct {
+	keyword Token
+	path    Token
+	name    Token
+}
+
+func (i *Import) Accept(visitor stmtVisitor) any {
+	return visitor.VisitImportStmt(i)
+}
+
+type While struct {
+	keyword   Token
+	condition Expr
+	body      Stmt
+	increment Expr
+}
+
+func (w *While) Accept(visitor stmtVisitor) any {
+	return visitor.VisitWhileStmt(w)
+}
+
+type Yield struct {
+	keyword Token
+	value   Expr
+}
+
+func (y *Yield) Accept(visitor stmtVisitor) any {
+	return visitor.VisitYieldStmt(y)
+}
@@ -1,4 +1,4 @@
-package main
+package lox
 
 import "fmt"
 
@@ -9,6 +9,16 @@ type Token struct {
 	lexeme    string
 	object    Object
 	line      int
+
+	// column of the first character of the lexeme, counted from 1
+	column int
+
+	// byte offset of the lexeme in the source
+	offset int
+
+	// path of the file the token was scanned from, empty for source that
+	// was not read from a file
+	file string
 }
 
 func (t Token) String() string {
@@ -1,4 +1,4 @@
-package main
+package lox
 
 type TokenType int
 
@@ -10,6 +10,9 @@ const (
 	LEFT_BRACE
 	RIGHT_BRACE
 
+	LEFT_BRACKET
+	RIGHT_BRACKET
+
 	COMMA
 	DOT
 	MINUS
@@ -17,6 +20,7 @@ const (
 	SEMICOLON
 	SLASH
 	STAR
+	PERCENT
 
 	QUESTION_MARK // for ternary
 	COLON         // for ternary
@@ -27,6 +31,7 @@ const (
 
 	EQUAL
 	EQUAL_EQUAL
+	ARROW // for arrow functions
 
 	GREATER
 	GREATER_EQUAL
@@ -37,25 +42,37 @@ const (
 	// Literals.
 	IDENTIFIER
 	STRING
+	INTERPOLATION // part of a string before an interpolated expression
 	NUMBER
 
 	// Keywords.
 	AND
+	BREAK
+	CATCH
 	CLASS
+	CONTINUE
 	ELSE
 	FALSE
+	FINALLY
 	FUN
 	FOR
 	IF
+	IMPORT
 	NIL
 	OR
 	PRINT
 	RETURN
 	SUPER
 	THIS
+	THROW
 	TRUE
+	TRY
 	VAR
 	WHILE
+	YIELD
+
+	// comments are kept apart from the other tokens, only the formatter reads them
+	COMMENT
 
 	EOF
 )
@@ -64,18 +81,28 @@ func keyword(s string) TokenType {
 	switch s {
 	case "and":
 		return AND
+	case "break":
+		return BREAK
+	case "catch":
+		return CATCH
 	case "class":
 		return CLASS
+	case "continue":
+		return CONTINUE
 	case "else":
 		return ELSE
 	case "false":
 		return FALSE
+	case "finally":
+		return FINALLY
 	case "for":
 		return FOR
 	case "fun":
 		return FUN
 	case "if":
 		return IF
+	case "import":
+		return IMPORT
 	case "nil":
 		return NIL
 	case "or":
@@ -88,12 +115,18 @@ func keyword(s string) TokenType {
 		return SUPER
 	case "this":
 		return THIS
+	case "throw":
+		return THROW
 	case "true":
 		return TRUE
+	case "try":
+		return TRY
 	case "var":
 		return VAR
 	case "while":
 		return WHILE
+	case "yield":
+		return YIELD
 	default:
 		return IDENTIFIER
 	}
@@ -109,6 +142,10 @@ func tokenTypeToString(t TokenType) string {
 		return "LEFT_BRACE"
 	case RIGHT_BRACE:
 		return "RIGHT_BRACE"
+	case LEFT_BRACKET:
+		return "LEFT_BRACKET"
+	case RIGHT_BRACKET:
+		return "RIGHT_BRACKET"
 	case COMMA:
 		return "COMMA"
 	case DOT:
@@ -123,6 +160,8 @@ func tokenTypeToString(t TokenType) string {
 		return "SLASH"
 	case STAR:
 		return "STAR"
+	case PERCENT:
+		return "PERCENT"
 	case BANG:
 		return "BANG"
 	case BANG_EQUAL:
@@ -131,6 +170,8 @@ func tokenTypeToString(t TokenType) string {
 		return "EQUAL"
 	case EQUAL_EQUAL:
 		return "EQUAL_EQUAL"
+	case ARROW:
+		return "ARROW"
 	case GREATER:
 		return "GREATER"
 	case GREATER_EQUAL:
@@ -143,22 +184,34 @@ func tokenTypeToString(t TokenType) string {
 		return "IDENTIFIER"
 	case STRING:
 		return "STRING"
+	case INTERPOLATION:
+		return "INTERPOLATION"
 	case NUMBER:
 		return "NUMBER"
 	case AND:
 		return "AND"
+	case BREAK:
+		return "BREAK"
+	case CATCH:
+		return "CATCH"
 	case CLASS:
 		return "CLASS"
+	case CONTINUE:
+		return "CONTINUE"
 	case ELSE:
 		return "ELSE"
 	case FALSE:
 		return "FALSE"
+	case FINALLY:
+		return "FINALLY"
 	case FUN:
 		return "FUN"
 	case FOR:
 		return "FOR"
 	case IF:
 		return "IF"
+	case IMPORT:
+		return "IMPORT"
 	case NIL:
 		return "NIL"
 	case OR:
@@ -171,12 +224,20 @@ func tokenTypeToString(t TokenType) string {
 		return "SUPER"
 	case THIS:
 		return "THIS"
+	case THROW:
+		return "THROW"
 	case TRUE:
 		return "TRUE"
+	case TRY:
+		return "TRY"
 	case VAR:
 		return "VAR"
 	case WHILE:
 		return "WHILE"
+	case YIELD:
+		return "YIELD"
+	case COMMENT:
+		return "COMMENT"
 	case EOF:
 		return "EOF"
 	default:
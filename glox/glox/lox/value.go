@@ -0,0 +1,143 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rules for lox values shared by the tree-walking Interpreter and the VM
+
+func isTruthy(obj Object) bool {
+	if obj == nil {
+		return false
+	}
+
+	b, ok := obj.(bool)
+	if ok {
+		return b
+	}
+
+	return true
+}
+
+func isEqual(a Object, b Object) bool {
+	return equal(a, b, nil)
+}
+
+// compares like isEqual, comparing holds the pairs of lists and maps being
+// compared further up, a pair met again is taken as equal so that lists and
+// maps that contain themselves are compared without looping forever
+func equal(a Object, b Object, comparing map[[2]any]bool) bool { //todo simplify, this is go not java
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil {
+		return false
+	}
+
+	if aList, ok := a.(*LoxList); ok {
+		bList, ok := b.(*LoxList)
+		return ok && aList.equals(bList, comparing)
+	}
+
+	if aMap, ok := a.(*LoxMap); ok {
+		bMap, ok := b.(*LoxMap)
+		return ok && aMap.equals(bMap, comparing)
+	}
+
+	return a == b
+}
+
+// converts a value to the text that print shows
+func stringify(obj Object) string {
+	if obj == nil {
+		return "nil"
+	}
+	return fmt.Sprint(obj)
+}
+
+// converts a value inside a list or a map to text, strings are quoted,
+// formatting holds the lists and maps being formatted further up which are
+// shown as [...] and {...} when one contains itself
+func formatElement(obj Object, formatting map[any]bool) string {
+	switch obj := obj.(type) {
+	case string:
+		return "\"" + obj + "\""
+	case *LoxList:
+		return obj.format(formatting)
+	case *LoxMap:
+		return obj.format(formatting)
+	}
+	return stringify(obj)
+}
+
+// formats a number that is concatenated to a string
+func numberToString(n float64) string {
+	s := strconv.FormatFloat(n, 'f', 6, 64)
+	return strings.TrimSuffix(s, ".000000")
+}
+
+// adds two numbers or concatenates two strings or two lists, a number concatenated
+// to a string is formatted with numberToString, returns false for any other operands
+func add(left any, right any) (any, bool) {
+	leftList, leftListOk := left.(*LoxList)
+	rightList, rightListOk := right.(*LoxList)
+	if leftListOk && rightListOk {
+		elements := make([]any, 0, len(leftList.elements)+len(rightList.elements))
+		elements = append(elements, leftList.elements...)
+		return NewLoxList(append(elements, rightList.elements...)), true
+	}
+
+	leftString, leftStringOk := left.(string)
+	rightString, rightStringOk := right.(string)
+	leftNumber, leftNumberOk := left.(float64)
+	rightNumber, rightNumberOk := right.(float64)
+
+	switch {
+	case leftStringOk && rightStringOk:
+		return leftString + rightString, true
+	case leftNumberOk && rightNumberOk:
+		return leftNumber + rightNumber, true
+	case leftStringOk && rightNumberOk:
+		return leftString + numberToString(rightNumber), true
+	case rightStringOk && leftNumberOk:
+		return numberToString(leftNumber) + rightString, true
+	default:
+		return nil, false
+	}
+}
+
+// reads object[index] of a list or a map
+func getIndex(object any, index any) (any, error) {
+	switch object := object.(type) {
+	case *LoxList:
+		position, err := object.position(index)
+		if err != nil {
+			return nil, err
+		}
+		return object.elements[position], nil
+	case *LoxMap:
+		return object.get(index)
+	default:
+		return nil, errors.New("Only lists and maps can be indexed.")
+	}
+}
+
+// assigns object[index] = value of a list or a map
+func setIndex(object any, index any, value any) error {
+	switch object := object.(type) {
+	case *LoxList:
+		position, err := object.position(index)
+		if err != nil {
+			return err
+		}
+		object.elements[position] = value
+		return nil
+	case *LoxMap:
+		return object.set(index, value)
+	default:
+		return errors.New("Only lists and maps can be indexed.")
+	}
+}
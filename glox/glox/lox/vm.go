@@ -0,0 +1,809 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// calls deeper than this are reported as a stack overflow
+const maxFrames = 10000
+
+// VM runs the bytecode produced by the Compiler, it is an alternative to the
+// tree-walking Interpreter and gives the same results
+type VM struct {
+	// globals of the script, those of modules are kept by their closures
+	globals map[string]any
+
+	// natives and whatever the embedder defined, seen by the script and every module
+	builtins map[string]any
+
+	// the modules imported so far
+	modules *moduleLoader
+
+	// the fiber currently running, the script fiber or the one of a generator
+	fiber *vmFiber
+
+	// where print writes to
+	stdout io.Writer
+
+	// state of the I/O natives
+	io *ioNatives
+}
+
+func NewVM() *VM {
+	vm := &VM{
+		globals:  make(map[string]any, 16),
+		builtins: make(map[string]any, 64),
+		stdout:   os.Stdout,
+		io:       newIONatives(),
+	}
+	vm.modules = newModuleLoader(vm.io)
+
+	defineNatives(vm, vm.io)
+	return vm
+}
+
+func (vm *VM) Run(source string) error {
+	_, err := vm.run(source, "", false)
+	return err
+}
+
+func (vm *VM) RunFile(path string) error {
+	return vm.modules.runScript(path, func(source string) error {
+		_, err := vm.run(source, path, false)
+		return err
+	})
+}
+
+func (vm *VM) Eval(source string) (Value, error) {
+	return vm.run(source, "", true)
+}
+
+func (vm *VM) Define(name string, value Value) {
+	vm.builtins[name] = value
+}
+
+func (vm *VM) DefineNative(name string, arity int, function NativeFunc) {
+	vm.Define(name, &NativeFunction{name, arity, function})
+}
+
+func (vm *VM) SetOutput(w io.Writer) {
+	vm.stdout = w
+}
+
+func (vm *VM) SetInput(r io.Reader) {
+	vm.io.setInput(r)
+}
+
+func (vm *VM) SetSandbox(sandbox Sandbox) {
+	vm.io.sandbox = sandbox
+}
+
+// Close has nothing to end, the fibers of unfinished generators are collected
+// once nothing refers to them
+func (vm *VM) Close() {}
+
+func (vm *VM) run(source string, file string, eval bool) (Value, error) {
+	statements, err := parse(source, file)
+	if err != nil {
+		return nil, err
+	}
+	return vm.interpret(statements, file, eval)
+}
+
+// compiles and runs the resolved statements, with eval set the value of a
+// final expression statement is returned
+func (vm *VM) Interpret(statements []Stmt, eval bool) (Value, error) {
+	return vm.interpret(statements, "", eval)
+}
+
+func (vm *VM) interpret(statements []Stmt, file string, eval bool) (Value, error) {
+	function, err := vm.compile(statements, file, eval)
+	if err != nil {
+		return nil, err
+	}
+
+	closure := &vmClosure{function: function, globals: vm.globals}
+	vm.fiber = &vmFiber{
+		stack:  []any{closure},
+		frames: []vmCallFrame{{closure: closure, ip: 0, base: 0}},
+	}
+
+	value, err := vm.execute()
+	if err != nil {
+		// the modules an uncaught error escaped from never finished
+		vm.modules.loading = nil
+		return nil, err
+	}
+	return value, nil
+}
+
+func (vm *VM) compile(statements []Stmt, file string, eval bool) (*vmFunction, error) {
+	function, err := NewCompiler(file).Compile(statements, eval)
+	if err != nil {
+		return nil, err
+	}
+
+	if printBytecode {
+		fmt.Print(disassembleFunction(function))
+	}
+	return function, nil
+}
+
+// disassembles the function and every function nested in it
+func disassembleFunction(function *vmFunction) string {
+	listing := function.chunk.disassemble(function.String())
+	for _, constant := range function.chunk.constants {
+		if nested, ok := constant.(*vmFunction); ok {
+			listing += disassembleFunction(nested)
+		}
+	}
+	return listing
+}
+
+// runs the current fiber until the script returns, errors raised inside of
+// a try statement continue at its handler
+func (vm *VM) execute() (any, error) {
+	for {
+		value, err := vm.dispatch()
+		runtimeErr, ok := err.(*RuntimeError)
+		if !ok || !vm.handle(runtimeErr) {
+			return value, err
+		}
+	}
+}
+
+// unwinds to the innermost handler of the running fiber, an error that escapes
+// the body of a generator finishes it and goes on to the fiber that resumed it,
+// reports whether a handler was found
+func (vm *VM) handle(err *RuntimeError) bool {
+	fiber := vm.fiber
+
+	for len(fiber.handlers) == 0 {
+		if fiber.generator == nil {
+			return false
+		}
+
+		fiber.generator.running = false
+		fiber.generator.finished = true
+		vm.dropFrames(fiber, 0)
+
+		caller := fiber.caller
+		fiber.caller = nil
+		fiber = caller
+	}
+
+	handler := fiber.handlers[len(fiber.handlers)-1]
+	fiber.handlers = fiber.handlers[:len(fiber.handlers)-1]
+
+	vm.closeUpvalues(fiber, handler.stackHeight)
+	fiber.stack = fiber.stack[:handler.stackHeight]
+	vm.dropFrames(fiber, handler.frames)
+	fiber.frames[len(fiber.frames)-1].ip = handler.ip
+
+	// OP_CATCH turns it into the value the catch clause binds, OP_THROW raises it again
+	fiber.push(err)
+	vm.fiber = fiber
+	return true
+}
+
+// cuts the frames of the fiber back to the given number, the imports of
+// the modules whose top-level code is dropped are given up
+func (vm *VM) dropFrames(fiber *vmFiber, frames int) {
+	for _, frame := range fiber.frames[frames:] {
+		if frame.module != nil {
+			vm.modules.end()
+		}
+	}
+	fiber.frames = fiber.frames[:frames]
+}
+
+// runs instructions until the script returns or an error is raised, the
+// error is either a *RuntimeError or the CompileErrors of an imported module
+func (vm *VM) dispatch() (any, error) {
+	fiber := vm.fiber
+	frame := &fiber.frames[len(fiber.frames)-1]
+	chunk := &frame.closure.function.chunk
+
+	// called after anything that can switch the frame or the fiber
+	reload := func() {
+		fiber = vm.fiber
+		frame = &fiber.frames[len(fiber.frames)-1]
+		chunk = &frame.closure.function.chunk
+	}
+
+	readShort := func() int {
+		frame.ip += 2
+		return int(chunk.code[frame.ip-2])<<8 | int(chunk.code[frame.ip-1])
+	}
+
+	readString := func() string {
+		return chunk.constants[readShort()].(string)
+	}
+
+	for {
+		op := OpCode(chunk.code[frame.ip])
+		frame.ip++
+
+		switch op {
+		case OP_CONSTANT:
+			fiber.push(chunk.constants[readShort()])
+		case OP_NIL:
+			fiber.push(nil)
+		case OP_TRUE:
+			fiber.push(true)
+		case OP_FALSE:
+			fiber.push(false)
+		case OP_UNINITIALIZED:
+			fiber.push(vmUninitialized{})
+		case OP_POP:
+			fiber.pop()
+
+		case OP_GET_LOCAL:
+			slot := int(chunk.code[frame.ip])
+			frame.ip++
+			fiber.push(fiber.stack[frame.base+slot])
+		case OP_SET_LOCAL:
+			slot := int(chunk.code[frame.ip])
+			frame.ip++
+			fiber.stack[frame.base+slot] = fiber.peek(0)
+		case OP_GET_GLOBAL:
+			name := readString()
+			value, ok := frame.closure.globals[name]
+			if !ok {
+				value, ok = vm.builtins[name]
+			}
+			if !ok {
+				return nil, vm.runtimeError("Undefined variable '" + name + "'.")
+			}
+			if _, ok := value.(vmUninitialized); ok {
+				return nil, vm.runtimeError("Uninitialized variable '" + name + "'.")
+			}
+			fiber.push(value)
+		case OP_DEFINE_GLOBAL:
+			frame.closure.globals[readString()] = fiber.pop()
+		case OP_SET_GLOBAL:
+			name := readString()
+			globals := frame.closure.globals
+			if _, ok := globals[name]; !ok {
+				if _, ok := vm.builtins[name]; !ok {
+					return nil, vm.runtimeError("Undefined variable '" + name + "'.")
+				}
+				globals = vm.builtins
+			}
+			globals[name] = fiber.peek(0)
+		case OP_GET_UPVALUE:
+			index := chunk.code[frame.ip]
+			frame.ip++
+			fiber.push(frame.closure.upvalues[index].get())
+		case OP_SET_UPVALUE:
+			index := chunk.code[frame.ip]
+			frame.ip++
+			frame.closure.upvalues[index].set(fiber.peek(0))
+		case OP_CHECK_INITIALIZED:
+			name := readString()
+			if _, ok := fiber.peek(0).(vmUninitialized); ok {
+				return nil, vm.runtimeError("Uninitialized variable '" + name + "'.")
+			}
+
+		case OP_GET_PROPERTY:
+			name := readString()
+			value, err := vm.getProperty(fiber.peek(0), name)
+			if err != nil {
+				return nil, err
+			}
+			fiber.pop()
+			fiber.push(value)
+		case OP_SET_PROPERTY:
+			instance, ok := fiber.peek(1).(*vmInstance)
+			if !ok {
+				return nil, vm.runtimeError("Only instances have fields.")
+			}
+			value := fiber.pop()
+			instance.fields[readString()] = value
+			fiber.pop()
+			fiber.push(value)
+
+		case OP_INTERPOLATE:
+			count := readShort()
+			var text strings.Builder
+			for _, part := range fiber.stack[len(fiber.stack)-count:] {
+				text.WriteString(stringify(part))
+			}
+			fiber.stack = fiber.stack[:len(fiber.stack)-count]
+			fiber.push(text.String())
+		case OP_LIST:
+			count := readShort()
+			elements := make([]any, count)
+			copy(elements, fiber.stack[len(fiber.stack)-count:])
+			fiber.stack = fiber.stack[:len(fiber.stack)-count]
+			fiber.push(NewLoxList(elements))
+		case OP_MAP:
+			count := readShort()
+			entries := fiber.stack[len(fiber.stack)-2*count:]
+
+			m := NewLoxMap()
+			for i := 0; i < len(entries); i += 2 {
+				if err := m.set(entries[i], entries[i+1]); err != nil {
+					return nil, vm.runtimeError(err.Error())
+				}
+			}
+
+			fiber.stack = fiber.stack[:len(fiber.stack)-2*count]
+			fiber.push(m)
+		case OP_GET_INDEX:
+			value, err := getIndex(fiber.peek(1), fiber.peek(0))
+			if err != nil {
+				return nil, vm.runtimeError(err.Error())
+			}
+			fiber.stack = fiber.stack[:len(fiber.stack)-2]
+			fiber.push(value)
+		case OP_SET_INDEX:
+			value := fiber.peek(0)
+			if err := setIndex(fiber.peek(2), fiber.peek(1), value); err != nil {
+				return nil, vm.runtimeError(err.Error())
+			}
+			fiber.stack = fiber.stack[:len(fiber.stack)-3]
+			fiber.push(value)
+
+		case OP_EQUAL:
+			right := fiber.pop()
+			left := fiber.pop()
+			fiber.push(isEqual(left, right))
+		case OP_NOT_EQUAL:
+			right := fiber.pop()
+			left := fiber.pop()
+			fiber.push(!isEqual(left, right))
+		case OP_GREATER, OP_GREATER_EQUAL, OP_LESS, OP_LESS_EQUAL, OP_SUBTRACT, OP_MULTIPLY, OP_DIVIDE, OP_MODULO:
+			right, rightOk := fiber.peek(0).(float64)
+			left, leftOk := fiber.peek(1).(float64)
+			if !(leftOk && rightOk) {
+				return nil, vm.runtimeError("Operands must be numbers.")
+			}
+			fiber.stack = fiber.stack[:len(fiber.stack)-2]
+
+			switch op {
+			case OP_GREATER:
+				fiber.push(left > right)
+			case OP_GREATER_EQUAL:
+				fiber.push(left >= right)
+			case OP_LESS:
+				fiber.push(left < right)
+			case OP_LESS_EQUAL:
+				fiber.push(left <= right)
+			case OP_SUBTRACT:
+				fiber.push(left - right)
+			case OP_MULTIPLY:
+				fiber.push(left * right)
+			case OP_DIVIDE:
+				if right == 0 {
+					return nil, vm.runtimeError("Cannot divide by zero.")
+				}
+				fiber.push(left / right)
+			case OP_MODULO:
+				if right == 0 {
+					return nil, vm.runtimeError("Cannot divide by zero.")
+				}
+				fiber.push(math.Mod(left, right))
+			}
+		case OP_ADD:
+			result, ok := add(fiber.peek(1), fiber.peek(0))
+			if !ok {
+				return nil, vm.runtimeError("Operands must be two numbers or strings and a number.")
+			}
+			fiber.stack = fiber.stack[:len(fiber.stack)-2]
+			fiber.push(result)
+		case OP_NOT:
+			fiber.push(!isTruthy(fiber.pop()))
+		case OP_NEGATE:
+			n, ok := fiber.peek(0).(float64)
+			if !ok {
+				return nil, vm.runtimeError("Operand must be a number.")
+			}
+			fiber.stack[len(fiber.stack)-1] = -n
+
+		case OP_PRINT:
+			fmt.Fprintln(vm.stdout, stringify(fiber.pop()))
+		case OP_JUMP:
+			offset := readShort()
+			frame.ip += offset
+		case OP_JUMP_IF_FALSE:
+			offset := readShort()
+			if !isTruthy(fiber.peek(0)) {
+				frame.ip += offset
+			}
+		case OP_LOOP:
+			offset := readShort()
+			frame.ip -= offset
+
+		case OP_CALL:
+			argCount := int(chunk.code[frame.ip])
+			frame.ip++
+			if err := vm.callValue(fiber.peek(argCount), argCount); err != nil {
+				return nil, err
+			}
+			reload()
+		case OP_CLOSURE:
+			function := chunk.constants[readShort()].(*vmFunction)
+			closure := &vmClosure{
+				function: function,
+				upvalues: make([]*vmUpvalue, function.upvalueCount),
+				globals:  frame.closure.globals,
+			}
+
+			for i := range closure.upvalues {
+				isLocal := chunk.code[frame.ip]
+				index := int(chunk.code[frame.ip+1])
+				frame.ip += 2
+
+				if isLocal == 1 {
+					closure.upvalues[i] = vm.captureUpvalue(fiber, frame.base+index)
+				} else {
+					closure.upvalues[i] = frame.closure.upvalues[index]
+				}
+			}
+
+			fiber.push(closure)
+		case OP_CLOSE_UPVALUE:
+			vm.closeUpvalues(fiber, len(fiber.stack)-1)
+			fiber.pop()
+		case OP_RETURN:
+			result := fiber.pop()
+			vm.closeUpvalues(fiber, frame.base)
+
+			if frame.module != nil {
+				vm.modules.end()
+				vm.modules.loaded[frame.module.path] = frame.module
+				result = frame.module
+			}
+
+			fiber.stack = fiber.stack[:frame.base]
+			fiber.frames = fiber.frames[:len(fiber.frames)-1]
+
+			if len(fiber.frames) == 0 {
+				if fiber.generator == nil {
+					return result, nil
+				}
+
+				// the body of a generator has finished
+				fiber.generator.finished = true
+				vm.switchToCaller(fiber, nil)
+			} else {
+				fiber.push(result)
+			}
+			reload()
+		case OP_YIELD:
+			vm.switchToCaller(fiber, fiber.pop())
+			reload()
+
+		case OP_THROW:
+			switch value := fiber.pop().(type) {
+			case *RuntimeError:
+				return nil, value
+			case *LoxError:
+				return nil, value.err
+			default:
+				err := vm.runtimeError(stringify(value))
+				err.Value, err.thrown = value, true
+				return nil, err
+			}
+		case OP_TRY:
+			offset := readShort()
+			fiber.handlers = append(fiber.handlers, vmHandler{
+				frames:      len(fiber.frames),
+				stackHeight: len(fiber.stack),
+				ip:          frame.ip + offset,
+			})
+		case OP_END_TRY:
+			fiber.handlers = fiber.handlers[:len(fiber.handlers)-1]
+		case OP_CATCH:
+			err := fiber.pop().(*RuntimeError)
+			fiber.push(err.value())
+
+		case OP_IMPORT:
+			if err := vm.importModule(readString()); err != nil {
+				return nil, err
+			}
+			reload()
+
+		case OP_CLASS:
+			fiber.push(&vmClass{
+				name:    readString(),
+				methods: make(map[string]*vmClosure, 8),
+			})
+		case OP_METHOD:
+			method := fiber.peek(0).(*vmClosure)
+			class := fiber.peek(1).(*vmClass)
+			class.methods[readString()] = method
+			fiber.pop()
+
+		default:
+			return nil, vm.runtimeError("Unknown opcode " + op.String() + ".")
+		}
+	}
+}
+
+func (vm *VM) runtimeError(message string) *RuntimeError {
+	err := &RuntimeError{Message: message}
+
+	// the token of the instruction that failed, underlined by FormatError
+	var lexeme string
+
+	// the frames of a generator are followed by those of the fiber that resumed it
+	for fiber := vm.fiber; fiber != nil; fiber = fiber.caller {
+		for i := len(fiber.frames) - 1; i >= 0; i-- {
+			frame := fiber.frames[i]
+			chunk := frame.closure.function.chunk
+
+			name := frame.closure.function.name
+			if name == "" {
+				name = "script"
+			}
+
+			if len(err.Trace) == 0 {
+				lexeme = chunk.lexemes[frame.ip-1]
+			}
+			err.Trace = append(err.Trace, StackFrame{name, chunk.lines[frame.ip-1], chunk.columns[frame.ip-1], frame.closure.function.file})
+		}
+	}
+
+	err.Token = Token{lexeme: lexeme, line: err.Trace[0].Line, column: err.Trace[0].Column, file: err.Trace[0].File}
+	return err
+}
+
+// pushes the namespace of the module, a module imported for the first time
+// is compiled and its top-level code called like a function, OP_RETURN then
+// replaces its result with the namespace
+func (vm *VM) importModule(path string) error {
+	fiber := vm.fiber
+	frame := &fiber.frames[len(fiber.frames)-1]
+
+	file, absolute, err := vm.modules.locate(frame.closure.function.file, path)
+	if err != nil {
+		return vm.runtimeError(err.Error())
+	}
+	if module, ok := vm.modules.loaded[absolute]; ok {
+		fiber.push(module)
+		return nil
+	}
+
+	if len(fiber.frames) == maxFrames {
+		return vm.runtimeError("Stack overflow.")
+	}
+
+	source, err := vm.modules.begin(path, file, absolute)
+	if err != nil {
+		return vm.runtimeError(err.Error())
+	}
+
+	function, err := vm.compileModule(source, file)
+	if err != nil {
+		vm.modules.end()
+		return err
+	}
+
+	globals := make(map[string]any, 16)
+	closure := &vmClosure{function: function, globals: globals}
+	module := &LoxModule{
+		name: moduleName(file),
+		path: absolute,
+		lookup: func(name string) (any, bool) {
+			value, ok := globals[name]
+			if _, uninitialized := value.(vmUninitialized); uninitialized {
+				return nil, false
+			}
+			return value, ok
+		},
+	}
+
+	fiber.push(closure)
+	fiber.frames = append(fiber.frames, vmCallFrame{
+		closure: closure,
+		ip:      0,
+		base:    len(fiber.stack) - 1,
+		module:  module,
+	})
+	return nil
+}
+
+func (vm *VM) compileModule(source string, file string) (*vmFunction, error) {
+	statements, err := parse(source, file)
+	if err != nil {
+		return nil, err
+	}
+
+	function, err := vm.compile(statements, file, false)
+	if err != nil {
+		return nil, err
+	}
+	function.name = "module"
+	return function, nil
+}
+
+func (vm *VM) getProperty(object any, name string) (any, *RuntimeError) {
+	switch object := object.(type) {
+	case *vmInstance:
+		if value, ok := object.fields[name]; ok {
+			return value, nil
+		}
+		if method, ok := object.class.methods[name]; ok {
+			return &vmBoundMethod{object, method}, nil
+		}
+	case *vmGenerator:
+		if name == "next" || name == "done" {
+			return vmGeneratorMethod{object, name}, nil
+		}
+	case *LoxModule:
+		if value, ok := object.lookup(name); ok {
+			return value, nil
+		}
+	case *LoxError:
+		if value, ok := object.field(name); ok {
+			return value, nil
+		}
+	default:
+		return nil, vm.runtimeError("Only instances have properties.")
+	}
+
+	return nil, vm.runtimeError("Undefined property '" + name + "'.")
+}
+
+// calls the value argCount slots below the top of the stack with the values above it
+func (vm *VM) callValue(callee any, argCount int) *RuntimeError {
+	fiber := vm.fiber
+
+	switch callee := callee.(type) {
+	case *vmClosure:
+		return vm.call(callee, argCount)
+	case *vmBoundMethod:
+		fiber.stack[len(fiber.stack)-argCount-1] = callee.receiver
+		return vm.call(callee.method, argCount)
+	case *vmClass:
+		instance := &vmInstance{
+			class:  callee,
+			fields: make(map[string]any, 8),
+		}
+		fiber.stack[len(fiber.stack)-argCount-1] = instance
+
+		if initializer, ok := callee.methods["init"]; ok {
+			return vm.call(initializer, argCount)
+		}
+		if argCount != 0 {
+			return vm.arityError(0, argCount)
+		}
+		return nil
+	case vmGeneratorMethod:
+		if argCount != 0 {
+			return vm.arityError(0, argCount)
+		}
+		fiber.pop()
+
+		if callee.name == "done" {
+			fiber.push(callee.generator.finished)
+			return nil
+		}
+		return vm.resume(callee.generator)
+	case *NativeFunction:
+		if argCount != callee.arity {
+			return vm.arityError(callee.arity, argCount)
+		}
+
+		arguments := make([]any, argCount)
+		copy(arguments, fiber.stack[len(fiber.stack)-argCount:])
+
+		value, err := callee.function(arguments)
+		if err != nil {
+			return vm.runtimeError(err.Error())
+		}
+
+		fiber.stack = fiber.stack[:len(fiber.stack)-argCount-1]
+		fiber.push(value)
+		return nil
+	default:
+		return vm.runtimeError("Can only call functions and classes.")
+	}
+}
+
+func (vm *VM) arityError(arity int, argCount int) *RuntimeError {
+	return vm.runtimeError("Expected " + strconv.Itoa(arity) + " argument but got " + strconv.Itoa(argCount) + ".")
+}
+
+func (vm *VM) call(closure *vmClosure, argCount int) *RuntimeError {
+	if argCount != closure.function.arity {
+		return vm.arityError(closure.function.arity, argCount)
+	}
+
+	fiber := vm.fiber
+	base := len(fiber.stack) - argCount - 1
+
+	// calling a generator function only creates the generator, the callee and
+	// the arguments move to its own fiber where the body will run
+	if closure.function.isGenerator {
+		generator := &vmGenerator{closure: closure}
+		generator.fiber = &vmFiber{
+			stack:     append(make([]any, 0, 16), fiber.stack[base:]...),
+			frames:    []vmCallFrame{{closure: closure, ip: 0, base: 0}},
+			generator: generator,
+		}
+
+		fiber.stack = fiber.stack[:base]
+		fiber.push(generator)
+		return nil
+	}
+
+	if len(fiber.frames) == maxFrames {
+		return vm.runtimeError("Stack overflow.")
+	}
+
+	fiber.frames = append(fiber.frames, vmCallFrame{
+		closure: closure,
+		ip:      0,
+		base:    base,
+	})
+	return nil
+}
+
+// continues the body of the generator, the value it yields next is pushed
+// on the stack of the fiber that resumed it
+func (vm *VM) resume(generator *vmGenerator) *RuntimeError {
+	if generator.finished {
+		vm.fiber.push(nil)
+		return nil
+	}
+
+	if generator.running {
+		return vm.runtimeError("Generator is already running.")
+	}
+
+	generator.running = true
+	generator.fiber.caller = vm.fiber
+	vm.fiber = generator.fiber
+	return nil
+}
+
+// suspends the fiber of a generator and gives the value to the fiber that resumed it
+func (vm *VM) switchToCaller(fiber *vmFiber, value any) {
+	fiber.generator.running = false
+
+	caller := fiber.caller
+	fiber.caller = nil
+
+	vm.fiber = caller
+	caller.push(value)
+}
+
+func (vm *VM) captureUpvalue(fiber *vmFiber, slot int) *vmUpvalue {
+	var previous *vmUpvalue
+	upvalue := fiber.openUpvalues
+
+	for upvalue != nil && upvalue.slot > slot {
+		previous = upvalue
+		upvalue = upvalue.next
+	}
+
+	if upvalue != nil && upvalue.slot == slot {
+		return upvalue
+	}
+
+	created := &vmUpvalue{fiber: fiber, slot: slot, next: upvalue}
+	if previous == nil {
+		fiber.openUpvalues = created
+	} else {
+		previous.next = created
+	}
+	return created
+}
+
+// closes every open upvalue of the fiber pointing at slot or above it
+func (vm *VM) closeUpvalues(fiber *vmFiber, slot int) {
+	for fiber.openUpvalues != nil && fiber.openUpvalues.slot >= slot {
+		upvalue := fiber.openUpvalues
+		upvalue.closed = fiber.stack[upvalue.slot]
+		upvalue.fiber = nil
+		fiber.openUpvalues = upvalue.next
+	}
+}
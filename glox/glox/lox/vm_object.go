@@ -0,0 +1,162 @@
+package lox
+
+// runtime values that only exist in the bytecode VM, numbers, strings, booleans
+// and nil are the same go values the tree-walking Interpreter uses
+
+// vmFunction is a compiled function, the script itself is a function without name
+type vmFunction struct {
+	name         string
+	arity        int
+	upvalueCount int
+	chunk        Chunk
+
+	// path of the file the function is declared in, empty for Run and Eval
+	file string
+
+	isGenerator bool
+}
+
+func (f *vmFunction) String() string {
+	if f.name == "" {
+		return "<script>"
+	}
+	return "<fn " + f.name + ">"
+}
+
+// vmUpvalue is a variable captured by a closure, while the variable is still
+// on the stack of its fiber the upvalue points at the slot, once the variable
+// goes out of scope the value is moved into the upvalue
+type vmUpvalue struct {
+	fiber  *vmFiber // nil once closed
+	slot   int
+	closed any
+
+	// next open upvalue of the same fiber, ordered by slot from the top of the stack
+	next *vmUpvalue
+}
+
+func (u *vmUpvalue) get() any {
+	if u.fiber != nil {
+		return u.fiber.stack[u.slot]
+	}
+	return u.closed
+}
+
+func (u *vmUpvalue) set(value any) {
+	if u.fiber != nil {
+		u.fiber.stack[u.slot] = value
+		return
+	}
+	u.closed = value
+}
+
+type vmClosure struct {
+	function *vmFunction
+	upvalues []*vmUpvalue
+
+	// the globals of the script or module the closure was created in
+	globals map[string]any
+}
+
+func (c *vmClosure) String() string {
+	return c.function.String()
+}
+
+type vmClass struct {
+	name    string
+	methods map[string]*vmClosure
+}
+
+func (c *vmClass) String() string {
+	return c.name
+}
+
+type vmInstance struct {
+	class  *vmClass
+	fields map[string]any
+}
+
+func (i *vmInstance) String() string {
+	return i.class.name + " instance"
+}
+
+type vmBoundMethod struct {
+	receiver *vmInstance
+	method   *vmClosure
+}
+
+func (b *vmBoundMethod) String() string {
+	return b.method.String()
+}
+
+type vmCallFrame struct {
+	closure *vmClosure
+	ip      int
+	base    int // stack slot of the callee, locals start right after it
+
+	// set for the top-level code of a module being imported, its return
+	// value is replaced with the namespace
+	module *LoxModule
+}
+
+// vmFiber is a value stack with its own call frames, the script runs on one fiber
+// and every generator gets another so that it can be suspended at a yield
+type vmFiber struct {
+	stack        []any
+	frames       []vmCallFrame
+	openUpvalues *vmUpvalue
+
+	// fiber that resumed this one and gets control back at the next yield
+	caller    *vmFiber
+	generator *vmGenerator
+
+	// the try statements being run, the innermost last
+	handlers []vmHandler
+}
+
+// vmHandler is where an error raised inside of a try statement continues, the
+// frames and the stack are cut back to what they were at its OP_TRY
+type vmHandler struct {
+	frames      int
+	stackHeight int
+	ip          int
+}
+
+func (f *vmFiber) push(value any) {
+	f.stack = append(f.stack, value)
+}
+
+func (f *vmFiber) pop() any {
+	value := f.stack[len(f.stack)-1]
+	f.stack = f.stack[:len(f.stack)-1]
+	return value
+}
+
+func (f *vmFiber) peek(distance int) any {
+	return f.stack[len(f.stack)-1-distance]
+}
+
+type vmGenerator struct {
+	closure *vmClosure
+	fiber   *vmFiber
+
+	running  bool
+	finished bool
+}
+
+func (g *vmGenerator) String() string {
+	return "<generator " + g.closure.function.name + ">"
+}
+
+// vmGeneratorMethod is "next" or "done" bound to a generator
+type vmGeneratorMethod struct {
+	generator *vmGenerator
+	name      string
+}
+
+func (m vmGeneratorMethod) String() string {
+	return "<native fn>"
+}
+
+// value of variables that were declared without an initializer and never assigned
+type vmUninitialized struct{}
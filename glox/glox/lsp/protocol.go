@@ -0,0 +1,56 @@
+package lsp
+
+// the parts of the protocol the server uses, see
+// https://microsoft.github.io/language-server-protocol/specifications/specification-current/
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string    `json:"uri"`
+	Range textRange `json:"range"`
+}
+
+type textDocument struct {
+	URI string `json:"uri"`
+}
+
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    textRange     `json:"range"`
+}
+
+// documents are sent whole on every change
+var initializeResult = map[string]any{
+	"capabilities": map[string]any{
+		"textDocumentSync":   1,
+		"definitionProvider": true,
+		"referencesProvider": true,
+		"hoverProvider":      true,
+	},
+	"serverInfo": map[string]any{"name": "glox"},
+}
@@ -0,0 +1,354 @@
+// Package lsp is a language server for Lox, it speaks the Language Server
+// Protocol over a pair of streams and answers from lox.Analyze
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daliborpovolny/lox/glox/glox/lox"
+)
+
+// json-rpc error codes
+const (
+	parseError     = -32700
+	invalidParams  = -32602
+	methodNotFound = -32601
+)
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// document is an open file as the editor sees it, unsaved changes included
+type document struct {
+	uri      string
+	lines    []string
+	analysis *lox.Analysis
+}
+
+func newDocument(uri string, text string) *document {
+	return &document{uri, strings.Split(text, "\n"), lox.Analyze(text)}
+}
+
+type server struct {
+	in  *textproto.Reader
+	out io.Writer
+
+	documents map[string]*document
+
+	shutdown bool
+}
+
+// Serve reads requests from in and writes responses and diagnostics to out
+// until the client sends exit, it fails when the client exits without
+// shutting the server down first or when a stream breaks
+func Serve(in io.Reader, out io.Writer) error {
+	s := &server{
+		in:        textproto.NewReader(bufio.NewReader(in)),
+		out:       out,
+		documents: make(map[string]*document, 8),
+	}
+
+	for {
+		msg, err := s.read()
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				if err := s.reply(nil, nil, &responseError{parseError, err.Error()}); err != nil {
+					return err
+				}
+				continue
+			}
+			if err == io.EOF && s.shutdown {
+				return nil
+			}
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit before shutdown")
+			}
+			return nil
+		}
+
+		result, respErr := s.handle(msg)
+
+		// notifications get no response
+		if msg.ID == nil {
+			continue
+		}
+		if err := s.reply(msg.ID, result, respErr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) read() (*message, error) {
+	header, err := s.in.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in.R, body); err != nil {
+		return nil, err
+	}
+
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (s *server) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *server) reply(id *json.RawMessage, result any, respErr *responseError) error {
+	// a null result must still be sent, omitempty would drop it
+	if result == nil && respErr == nil {
+		result = json.RawMessage("null")
+	}
+	if id == nil {
+		null := json.RawMessage("null")
+		id = &null
+	}
+	return s.write(&message{ID: id, Result: result, Error: respErr})
+}
+
+func (s *server) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(&message{Method: method, Params: raw})
+}
+
+func (s *server) handle(msg *message) (any, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		return initializeResult, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{invalidParams, err.Error()}
+		}
+		s.open(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+
+	case "textDocument/didChange":
+		// the server asks for full syncing so the last change is the whole text
+		var params struct {
+			TextDocument   textDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{invalidParams, err.Error()}
+		}
+		if changes := params.ContentChanges; len(changes) > 0 {
+			s.open(params.TextDocument.URI, changes[len(changes)-1].Text)
+		}
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument textDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{invalidParams, err.Error()}
+		}
+		delete(s.documents, params.TextDocument.URI)
+		s.publishDiagnostics(params.TextDocument.URI, nil)
+		return nil, nil
+
+	case "textDocument/definition":
+		doc, symbol, _, err := s.symbolAt(msg.Params)
+		if err != nil || symbol == nil {
+			return nil, err
+		}
+		return doc.location(symbol.Declaration), nil
+
+	case "textDocument/references":
+		var params struct {
+			Context struct {
+				IncludeDeclaration bool `json:"includeDeclaration"`
+			} `json:"context"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &responseError{invalidParams, err.Error()}
+		}
+		doc, symbol, _, respErr := s.symbolAt(msg.Params)
+		if respErr != nil {
+			return nil, respErr
+		}
+		locations := []location{}
+		if symbol == nil {
+			return locations, nil
+		}
+		if params.Context.IncludeDeclaration {
+			locations = append(locations, doc.location(symbol.Declaration))
+		}
+		for _, reference := range symbol.References {
+			locations = append(locations, doc.location(reference))
+		}
+		return locations, nil
+
+	case "textDocument/hover":
+		doc, symbol, at, err := s.symbolAt(msg.Params)
+		if err != nil || symbol == nil {
+			return nil, err
+		}
+		return hover{
+			Contents: markupContent{"markdown", "```lox\n" + symbol.Signature + "\n```"},
+			Range:    doc.rangeOf(at),
+		}, nil
+	}
+
+	if msg.ID != nil && !strings.HasPrefix(msg.Method, "$/") {
+		return nil, &responseError{methodNotFound, "Unknown method " + msg.Method + "."}
+	}
+	return nil, nil
+}
+
+// analyzes the new text of a document and sends its errors to the client
+func (s *server) open(uri string, text string) {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+	s.publishDiagnostics(uri, doc)
+}
+
+func (s *server) publishDiagnostics(uri string, doc *document) {
+	diagnostics := []diagnostic{}
+	if doc != nil {
+		for _, err := range doc.analysis.Diagnostics {
+			diagnostics = append(diagnostics, diagnostic{
+				Range:    doc.rangeOf(lox.Location{Line: err.Line, Column: err.Column, Length: err.Length}),
+				Severity: 1,
+				Source:   "glox",
+				Message:  "Error" + err.Where + ": " + err.Message,
+			})
+		}
+	}
+
+	// a broken output stream shows up on the next response
+	_ = s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{uri, diagnostics})
+}
+
+// finds the symbol under the cursor of a position request, along with the
+// location of the name the cursor is on
+func (s *server) symbolAt(raw json.RawMessage) (*document, *lox.Symbol, lox.Location, *responseError) {
+	var params struct {
+		TextDocument textDocument `json:"textDocument"`
+		Position     position     `json:"position"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, nil, lox.Location{}, &responseError{invalidParams, err.Error()}
+	}
+
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, nil, lox.Location{}, nil
+	}
+
+	line, column := doc.loxPosition(params.Position)
+	symbol := doc.analysis.SymbolAt(line, column)
+	if symbol == nil {
+		return doc, nil, lox.Location{}, nil
+	}
+
+	at := symbol.Declaration
+	for _, reference := range symbol.References {
+		if reference.Contains(line, column) {
+			at = reference
+		}
+	}
+	return doc, symbol, at, nil
+}
+
+// the client counts lines from 0 and characters in UTF-16 code units,
+// Lox counts both from 1 and columns in characters
+func (d *document) loxPosition(p position) (int, int) {
+	column := 1
+	if p.Line < len(d.lines) {
+		units := 0
+		for _, r := range d.lines[p.Line] {
+			if units >= p.Character {
+				break
+			}
+			units += utf16Length(r)
+			column++
+		}
+	}
+	return p.Line + 1, column
+}
+
+func (d *document) position(line int, column int) position {
+	character := 0
+	if line >= 1 && line <= len(d.lines) {
+		text := d.lines[line-1]
+		for i := 1; i < column && text != ""; i++ {
+			r, size := utf8.DecodeRuneInString(text)
+			character += utf16Length(r)
+			text = text[size:]
+		}
+	}
+	return position{max(line-1, 0), character}
+}
+
+func (d *document) rangeOf(l lox.Location) textRange {
+	return textRange{d.position(l.Line, l.Column), d.position(l.Line, l.Column+l.Length)}
+}
+
+func (d *document) location(l lox.Location) location {
+	return location{d.uri, d.rangeOf(l)}
+}
+
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func frame(messages ...string) io.Reader {
+	var in strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	return strings.NewReader(in.String())
+}
+
+func responses(t *testing.T, out string) []map[string]any {
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(out)))
+	var messages []map[string]any
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader.R, body); err != nil {
+			t.Fatal(err)
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+func TestServe(t *testing.T) {
+	// the emoji takes two UTF-16 code units, positions after it shift by one
+	text := "var s = \"😀\"; fun greet(name) { return s + name; }\ngreet(s);\nvar = 1;\n"
+	open, _ := json.Marshal(map[string]any{"textDocument": map[string]any{"uri": "file:///a.lox", "text": text}})
+	at := func(method string, id int, line int, character int) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":{"textDocument":{"uri":"file:///a.lox"},"position":{"line":%d,"character":%d},"context":{"includeDeclaration":true}}}`,
+			id, method, line, character)
+	}
+
+	var out strings.Builder
+	err := Serve(frame(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":`+string(open)+`}`,
+		at("textDocument/definition", 2, 1, 6),
+		at("textDocument/references", 3, 0, 4),
+		at("textDocument/hover", 4, 1, 1),
+		`{"jsonrpc":"2.0","id":5,"method":"textDocument/formatting","params":{}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := responses(t, out.String())
+	if len(messages) != 7 {
+		t.Fatalf("expected 7 messages, got %d: %v", len(messages), messages)
+	}
+
+	diagnostics := messages[1]["params"].(map[string]any)["diagnostics"].([]any)
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got %v", diagnostics)
+	}
+	start := diagnostics[0].(map[string]any)["range"].(map[string]any)["start"]
+	if fmt.Sprint(start) != "map[character:4 line:2]" {
+		t.Errorf("expected the diagnostic at 2:4, got %v", start)
+	}
+
+	// s is used after the emoji on the first line
+	definition := fmt.Sprint(messages[2]["result"])
+	if definition != "map[range:map[end:map[character:5 line:0] start:map[character:4 line:0]] uri:file:///a.lox]" {
+		t.Errorf("unexpected definition %v", definition)
+	}
+
+	references := messages[3]["result"].([]any)
+	if len(references) != 3 {
+		t.Fatalf("expected 3 references to s, got %v", references)
+	}
+	use := references[1].(map[string]any)["range"].(map[string]any)["start"]
+	if fmt.Sprint(use) != "map[character:39 line:0]" {
+		t.Errorf("expected the use of s at 0:39, got %v", use)
+	}
+
+	hover := messages[4]["result"].(map[string]any)["contents"].(map[string]any)["value"]
+	if hover != "```lox\nfun greet(name)\n```" {
+		t.Errorf("unexpected hover %q", hover)
+	}
+
+	if code := messages[5]["error"].(map[string]any)["code"]; code != float64(methodNotFound) {
+		t.Errorf("expected method not found, got %v", code)
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	err := Serve(frame(`{"jsonrpc":"2.0","method":"exit"}`), io.Discard)
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -2,39 +2,51 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
+	"net"
 	"os"
-	"os/exec"
-	"strconv"
+
+	"github.com/daliborpovolny/lox/glox/glox/dap"
+	"github.com/daliborpovolny/lox/glox/glox/golden"
+	"github.com/daliborpovolny/lox/glox/glox/lox"
+	"github.com/daliborpovolny/lox/glox/glox/lsp"
 )
 
 type Lox struct {
-	repl            bool
-	hadError        bool
-	hadRuntimeError bool
-
-	interpreter *Interpreter
+	// run scripts on the bytecode VM instead of the tree-walking interpreter
+	useVM bool
 }
 
 func NewLox() *Lox {
-	return &Lox{
-		hadError:        false,
-		hadRuntimeError: false,
-		interpreter:     NewInterpreter(),
-	}
+	return &Lox{}
 }
 
-var printParseTree bool = false
+func (l *Lox) Start(args []string) error {
+	if len(args) > 0 && args[0] == "--vm" {
+		l.useVM = true
+		args = args[1:]
+	}
 
-var lox *Lox = NewLox()
+	if len(args) > 0 && args[0] == "fmt" {
+		return l.formatFiles(args[1:])
+	}
+	if len(args) > 0 && args[0] == "test" {
+		return l.runTests(args[1:])
+	}
+	if len(args) > 0 && args[0] == "dap" {
+		return l.serveDebugAdapter(args[1:])
+	}
+	if len(args) == 2 && args[0] == "debug" {
+		return l.debugFile(args[1])
+	}
 
-func (l *Lox) Start(args []string) error {
 	if len(args) > 1 {
-		return fmt.Errorf("usage: golox [script] | test")
+		return fmt.Errorf("usage: golox [--vm] [script] | test [-update] [dir] | debug script | dap [-port N] | lsp | fmt [--check] [files]")
 	} else if len(args) == 1 {
-		if args[0] == "test" {
-			l.runTests()
-			return nil
+		if args[0] == "lsp" {
+			return lsp.Serve(os.Stdin, os.Stdout)
 		}
 
 		err := l.runFile(args[0])
@@ -47,149 +59,182 @@ func (l *Lox) Start(args []string) error {
 	return nil
 }
 
-func (l *Lox) runFile(path string) error {
-	bytes, err := os.ReadFile(path)
-	if err != nil {
-		return err
+func (l *Lox) newEngine() lox.Engine {
+	if l.useVM {
+		return lox.NewVM()
 	}
-	l.run(string(bytes))
+	return lox.NewInterpreter()
+}
 
-	if l.hadError {
-		os.Exit(65)
+func (l *Lox) runFile(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
 	}
 
-	if l.hadRuntimeError {
-		os.Exit(70)
+	// imports are relative to the directory of the file
+	if code := lox.RunScript(l.newEngine(), path, os.Stderr); code != 0 {
+		os.Exit(code)
 	}
-
 	return nil
 }
 
 func (l *Lox) runPrompt() {
-	l.repl = true
 	inputScanner := bufio.NewScanner(os.Stdin)
 
-	i := NewInterpreter()
+	engine := l.newEngine()
+	defer engine.Close()
 
 	for inputScanner.Scan() {
 		input := inputScanner.Text()
+		value, err := engine.Eval(input)
 
-		scanner := NewScanner(input)
-		tokens := scanner.scanTokens()
-
-		parser := NewParser(tokens)
-		statements := parser.Parse()
-		if l.hadError {
-			l.hadError = false
-			continue
+		if err != nil {
+			fmt.Fprintln(os.Stderr, lox.FormatError(err, "<stdin>", input))
+		} else if value != nil {
+			fmt.Println(value)
 		}
-
-		i.ReplInterpret(statements)
 	}
 }
 
-func (l *Lox) run(source string) {
-	scanner := NewScanner(source)
-	tokens := scanner.scanTokens()
-
-	parser := NewParser(tokens)
-	statements := parser.Parse()
-	if l.hadError {
-		return
+// formats the files in place, or the standard input onto the standard output
+// when no files are given, with --check nothing is written, the files that
+// are not formatted are listed and the exit code is 1 if there are any
+func (l *Lox) formatFiles(args []string) error {
+	check := len(args) > 0 && args[0] == "--check"
+	if check {
+		args = args[1:]
 	}
-	// fmt.Println("succesfully parsed")
 
-	if printParseTree {
-		astPrinter := AstPrinter{}
-		fmt.Println(astPrinter.Print(statements))
-	}
+	failed := false
+	unformatted := false
 
-	l.interpreter.Interpret(statements)
-	// fmt.Println("succesfully interpreted")
-
-}
+	format := func(path string, source []byte) (string, bool) {
+		formatted, err := lox.Format(string(source))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, lox.FormatError(err, path, string(source)))
+			failed = true
+			return "", false
+		}
+		if formatted != string(source) {
+			unformatted = true
+			return formatted, true
+		}
+		return formatted, false
+	}
 
-func (l *Lox) error(line int, message string) {
-	l.report(line, "", message)
-}
+	if len(args) == 0 {
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		formatted, _ := format("<stdin>", source)
+		if !check && !failed {
+			fmt.Print(formatted)
+		}
+	}
 
-func (l *Lox) report(line int, where string, message string) {
-	fmt.Fprintln(os.Stderr, "[line ", line, "] Error", where, ": ", message)
-	l.hadError = true
-}
+	for _, path := range args {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
 
-func (l *Lox) errorToken(token Token, message string) {
-	if token.tokenType == EOF {
-		l.report(token.line, " at end", message)
-	} else {
-		l.report(token.line, "at '"+token.lexeme+"'", message)
+		formatted, changed := format(path, source)
+		if !changed {
+			continue
+		}
+		if check {
+			fmt.Println(path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(formatted), 0644); err != nil {
+			return err
+		}
 	}
-}
 
-func (l *Lox) runTimeError(rErr RuntimeError) {
-	msg := rErr.Message
-	if !l.repl {
-		msg += "\n[line " + strconv.Itoa(rErr.Token.line) + "]"
+	if failed {
+		os.Exit(65)
 	}
-	fmt.Fprintln(os.Stderr, msg)
-	l.hadRuntimeError = false
-}
-
-// TESTFILES is a list of strings of test file names, for a test to work
-// it has a have a {name}.lox and {name}.out in the tests folder
-// the {name}.out file is what the output will be compared agains
-// the {name}.out file should end with a new line
-var TESTFILES []string = []string{
-	"hello",
-	"scope",
-	"math",
-	"precedance",
-	"comments",
-	"comma",
-	"compare",
-	"if",
-	"logicalops",
-	"while",
-	"function",
-	"return",
-	"fib",
+	if check && unformatted {
+		os.Exit(1)
+	}
+	return nil
 }
 
-// runs the test included in TESTFILES
-func (l *Lox) runTests() bool {
-
-	exec.Command("go", "build").Run()
-
-	for _, path := range TESTFILES {
-		cmd := exec.Command("./glox", "../tests/"+path+".lox")
-
-		outputBytes, err := cmd.Output()
-		if err != nil {
-			fmt.Println("error during test of "+path+":\n\t", string(outputBytes))
-			return false
-		}
+// runs the scripts of the tests directory on both backends and prints a
+// diff for every one that did not match its golden files, -update rewrites
+// the golden files instead, the exit code is 1 if any test failed
+func (l *Lox) runTests(args []string) error {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	update := flags.Bool("update", false, "rewrite the golden files with what the scripts do")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
 
-		desiredBytes, err := os.ReadFile("../tests/" + path + ".out")
+	dir := "../tests"
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
 
-		output := string(outputBytes)
-		desired := string(desiredBytes)
+	results, err := golden.Run(dir, *update)
+	if err != nil {
+		return err
+	}
 
-		if output != desired {
-			fmt.Println("test", path, "failed")
-			fmt.Println("\t expected:\n" + desired)
-			fmt.Println("\t actual:\n" + output)
-		} else {
-			fmt.Println("test", path, "passed")
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			fmt.Println("test", result.Name, "failed:", result.Err)
+		case !result.Passed():
+			fmt.Println("test", result.Name, "failed")
+			fmt.Print(result.Diff)
+		default:
+			fmt.Println("test", result.Name, "passed")
+			continue
 		}
+		failed++
 	}
 
-	return true
+	if failed > 0 {
+		fmt.Printf("%d of %d tests failed\n", failed, len(results))
+		os.Exit(1)
+	}
+	return nil
 }
 
 func main() {
-	err := lox.Start(os.Args[1:])
+	err := NewLox().Start(os.Args[1:])
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(64)
 	}
 }
+
+// speaks the Debug Adapter Protocol on the standard streams, or with -port
+// to the first client that connects to the local TCP port
+func (l *Lox) serveDebugAdapter(args []string) error {
+	flags := flag.NewFlagSet("dap", flag.ContinueOnError)
+	port := flags.Int("port", 0, "listen on the local TCP port instead of the standard streams")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *port == 0 {
+		return dap.Serve(os.Stdin, os.Stdout)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", *port))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "listening on", listener.Addr())
+
+	conn, err := listener.Accept()
+	listener.Close()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return dap.Serve(conn, conn)
+}
@@ -16,16 +16,25 @@ func main() {
 	outputDir := os.Args[1]
 
 	err := defineAst(outputDir, "Expr", []string{
-		"Assign		: Token name, Expr value",
+		"Assign		: Token name, Expr value, int depth",
 		"Binary		: Expr left, Token operator, Expr right",
 		"Call		: Expr callee, Token paren, []Expr arguments",
+		"Get		: Expr object, Token name",
 		"Grouping	: Expr expression",
+		"Index		: Expr object, Token bracket, Expr index",
+		"Interpolation	: []Expr parts",
+		"Lambda		: *Function function",
 		"Literal	: Object value",
+		"List		: Token bracket, []Expr elements",
 		"Logical	: Expr left, Token operator, Expr right",
+		"Map		: Token brace, []Expr keys, []Expr values",
+		"Set		: Expr object, Token name, Expr value",
+		"SetIndex	: Expr object, Token bracket, Expr index, Expr value",
+		"This		: Token keyword, int depth",
 		"Unary 		: Token operator, Expr right",
 		"Ternary	: Expr condition, Expr outcome1, Expr outcome2",
 		"Comma		: []Expr exprs",
-		"Variable	: Token name",
+		"Variable	: Token name, int depth",
 	})
 	if err != nil {
 		fmt.Println(err)
@@ -34,13 +43,20 @@ func main() {
 
 	err = defineAst(outputDir, "Stmt", []string{
 		"Block		: []Stmt statements",
+		"Break		: Token keyword",
+		"Class		: Token name, []*Function methods",
+		"Continue	: Token keyword",
 		"Expression	: Expr expression",
-		"Function	: Token name, []Token params, []Stmt body",
-		"Print		: Expr expression",
+		"Function	: Token name, []Token params, []Stmt body, bool isGenerator",
+		"Print		: Token keyword, Expr expression",
 		"Return		: Token keyword, Expr value",
+		"Throw		: Token keyword, Expr value",
+		"Try		: Token keyword, []Stmt body, Token catchName, []Stmt catchBody, []Stmt finallyBody, bool hasCatch",
 		"Var		: Token name, Expr initializer",
-		"If			: Expr condition, Stmt thenBranch, Stmt elseBranch",
-		"While      : Expr condition, Stmt body",
+		"If			: Token keyword, Expr condition, Stmt thenBranch, Stmt elseBranch",
+		"Import		: Token keyword, Token path, Token name",
+		"While      : Token keyword, Expr condition, Stmt body, Expr increment",
+		"Yield		: Token keyword, Expr value",
 	})
 	if err != nil {
 		fmt.Println(err)
@@ -58,7 +74,7 @@ func defineAst(outDir, baseName string, types []string) error {
 	}
 	defer f.Close()
 
-	fmt.Fprintln(f, "package main")
+	fmt.Fprintln(f, "package lox")
 
 	fmt.Fprintln(f, "")
 
@@ -66,7 +82,7 @@ func defineAst(outDir, baseName string, types []string) error {
 	fmt.Fprintln(f, "type "+strings.ToLower(baseName)+"Visitor interface {")
 	for _, t := range types {
 		name := strings.TrimSpace(strings.Split(t, ":")[0])
-		fmt.Fprintln(f, "Visit"+name+baseName+"("+strings.ToLower(baseName)+" "+name+") any")
+		fmt.Fprintln(f, "Visit"+name+baseName+"("+strings.ToLower(baseName)+" *"+name+") any")
 	}
 	fmt.Fprintln(f, "}")
 
@@ -96,8 +112,9 @@ func defineAst(outDir, baseName string, types []string) error {
 		}
 		fmt.Fprintf(f, "}\n\n")
 
-		// function
-		fmt.Fprintln(f, "func ("+strings.ToLower(name)[:1]+" "+name+") Accept(visitor "+strings.ToLower(baseName)+"Visitor) any {")
+		// function, nodes are used through pointers so that passes like the
+		// resolver can key side tables on node identity
+		fmt.Fprintln(f, "func ("+strings.ToLower(name)[:1]+" *"+name+") Accept(visitor "+strings.ToLower(baseName)+"Visitor) any {")
 		fmt.Fprintln(f, "return visitor.Visit"+name+baseName+"("+strings.ToLower(name)[:1]+")")
 		fmt.Fprintln(f, "}")
 